@@ -0,0 +1,201 @@
+package core
+
+import (
+	"slices"
+	"strings"
+)
+
+// Internal, a group of entities that have exactly the same set of component types.
+// Components are stored in columns, one column per component type, rows are entities.
+type archetype struct {
+	key   string
+	types []ComponentType
+
+	// Bitset of component type IDs, matched against query & finder masks.
+	signature bitset
+
+	// Column index by component type.
+	index   map[ComponentType]int
+	columns [][]Component
+
+	// Change ticks, same layout as columns.
+	ticks [][]_ComponentTicks
+
+	entities []*entityRef
+
+	// Cached transitions to the neighbour archetypes.
+	addEdges    map[ComponentType]*archetype
+	removeEdges map[ComponentType]*archetype
+}
+
+// Internal, world ticks when a component was attached & last changed.
+type _ComponentTicks struct {
+	added   uint64
+	changed uint64
+}
+
+// Internal, points to the entity row inside an archetype.
+type entityLocation struct {
+	arch *archetype
+	row  int
+}
+
+// Internal archetype constructor, types should be sorted & unique, signature should contain their IDs.
+func makeArchetype(types []ComponentType, signature bitset) *archetype {
+	a := &archetype{
+		key:         makeArchetypeKey(types),
+		types:       types,
+		signature:   signature,
+		index:       make(map[ComponentType]int, len(types)),
+		columns:     make([][]Component, len(types)),
+		ticks:       make([][]_ComponentTicks, len(types)),
+		entities:    make([]*entityRef, 0),
+		addEdges:    make(map[ComponentType]*archetype),
+		removeEdges: make(map[ComponentType]*archetype),
+	}
+
+	for i, t := range types {
+		a.index[t] = i
+		a.columns[i] = make([]Component, 0)
+		a.ticks[i] = make([]_ComponentTicks, 0)
+	}
+
+	return a
+}
+
+// Returns a unique archetype key for sorted component types.
+func makeArchetypeKey(types []ComponentType) string {
+	return strings.Join(types, "\x00")
+}
+
+// Returns true if the archetype contains provided component type.
+func (a *archetype) has(componentType ComponentType) bool {
+	_, ok := a.index[componentType]
+	return ok
+}
+
+// Returns entities count stored in the archetype.
+func (a *archetype) len() int {
+	return len(a.entities)
+}
+
+// Returns a component by row & type, may return nil.
+func (a *archetype) get(row int, componentType ComponentType) Component {
+	col, ok := a.index[componentType]
+
+	if !ok {
+		return nil
+	}
+
+	return a.columns[col][row]
+}
+
+// Returns component ticks by row & type, ok is false if the archetype has no such type.
+func (a *archetype) getTicks(row int, componentType ComponentType) (_ComponentTicks, bool) {
+	col, ok := a.index[componentType]
+
+	if !ok {
+		return _ComponentTicks{}, false
+	}
+
+	return a.ticks[col][row], true
+}
+
+// Appends a new row with empty components & ticks. Returns the row index.
+func (a *archetype) push(e *entityRef) int {
+	for i := range a.columns {
+		a.columns[i] = append(a.columns[i], nil)
+		a.ticks[i] = append(a.ticks[i], _ComponentTicks{})
+	}
+
+	a.entities = append(a.entities, e)
+	return len(a.entities) - 1
+}
+
+// Appends a copy of the source row, types missing in the source are left empty. Returns the row index.
+func (a *archetype) pushFrom(src *archetype, row int) int {
+	// both type lists are sorted, so columns are matched in a single pass
+	j := 0
+
+	for i, t := range a.types {
+		for j < len(src.types) && src.types[j] < t {
+			j++
+		}
+
+		var c Component
+		var ticks _ComponentTicks
+
+		if j < len(src.types) && src.types[j] == t {
+			c = src.columns[j][row]
+			ticks = src.ticks[j][row]
+		}
+
+		a.columns[i] = append(a.columns[i], c)
+		a.ticks[i] = append(a.ticks[i], ticks)
+	}
+
+	a.entities = append(a.entities, src.entities[row])
+	return len(a.entities) - 1
+}
+
+// Grows columns capacity for n more rows.
+func (a *archetype) reserve(n int) {
+	for i := range a.columns {
+		a.columns[i] = slices.Grow(a.columns[i], n)
+		a.ticks[i] = slices.Grow(a.ticks[i], n)
+	}
+
+	a.entities = slices.Grow(a.entities, n)
+}
+
+// Removes a row by swapping it with the last one.
+// Returns the entity that was moved into the removed row, or nil if no entity was moved.
+func (a *archetype) swapRemove(row int) *entityRef {
+	last := len(a.entities) - 1
+
+	for i := range a.columns {
+		a.columns[i][row] = a.columns[i][last]
+		a.columns[i][last] = nil
+		a.columns[i] = a.columns[i][:last]
+
+		a.ticks[i][row] = a.ticks[i][last]
+		a.ticks[i] = a.ticks[i][:last]
+	}
+
+	a.entities[row] = a.entities[last]
+	a.entities[last] = nil
+	a.entities = a.entities[:last]
+
+	if row == last {
+		return nil
+	}
+
+	return a.entities[row]
+}
+
+// Returns sorted types of the archetype extended with provided types.
+func (a *archetype) withTypes(componentTypes ...ComponentType) []ComponentType {
+	types := slices.Clone(a.types)
+
+	for _, t := range componentTypes {
+		if !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+
+	slices.Sort(types)
+	return types
+}
+
+// Returns sorted types of the archetype without provided types.
+func (a *archetype) withoutTypes(componentTypes ...ComponentType) []ComponentType {
+	types := make([]ComponentType, 0, len(a.types))
+
+	for _, t := range a.types {
+		if !slices.Contains(componentTypes, t) {
+			types = append(types, t)
+		}
+	}
+
+	return types
+}
@@ -0,0 +1,214 @@
+package core
+
+import (
+	"errors"
+	"maps"
+	"slices"
+)
+
+// Creates n entities with components returned by the factory for each entity index.
+// Entity slots & archetype rows are reserved up front, observers implementing BatchObserver are notified
+// once per component type. The factory is called before the store is changed.
+func (es *EntityStore) NewBatch(n int, factory func(i int) []Component) []Entity {
+	if n <= 0 {
+		return make([]Entity, 0)
+	}
+
+	components := make([][]Component, n)
+
+	for i := range components {
+		components[i] = factory(i)
+	}
+
+	changes := make([]entityChange, n)
+	entities := make([]Entity, n)
+
+	es.writeLock()
+	es.slots = slices.Grow(es.slots, n)
+
+	for i := range changes {
+		changes[i] = es.spawnRow(es.nextId(), components[i])
+		entities[i] = changes[i].e
+
+		// the rest entities likely share the archetype of the first one
+		if i == 0 {
+			loc, _ := es.location(changes[0].e.id)
+			loc.arch.reserve(n - 1)
+		}
+	}
+
+	es.writeUnlock()
+
+	es.notifyAttachedBatch(changes)
+	return entities
+}
+
+// Attaches copies of provided components to each entity (see CloneComponent), so entities don't share state.
+// Observers implementing BatchObserver are notified once per component type.
+// Returns joined ErrEntityNotAlive errors for removed entities, other entities are still changed.
+func (es *EntityStore) AddToMany(ids []EntityID, components ...Component) error {
+	clones := make([][]Component, len(ids))
+
+	for i := range ids {
+		clones[i] = make([]Component, len(components))
+
+		for j, c := range components {
+			clones[i][j] = CloneComponent(c)
+		}
+	}
+
+	changes := make([]entityChange, 0, len(ids))
+	errs := make([]error, 0)
+
+	es.writeLock()
+
+	for i, id := range ids {
+		change, err := es.addTo(id, clones[i])
+
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		changes = append(changes, change)
+	}
+
+	es.writeUnlock()
+
+	es.notifyAttachedBatch(changes)
+	return errors.Join(errs...)
+}
+
+// Detaches the component type from all entities, returns the number of changed entities.
+// Component hooks are called for each component, observers implementing BatchObserver are notified once.
+func (es *EntityStore) RemoveAll(componentType string) int {
+	es.readLock()
+
+	refs := make([]*entityRef, 0)
+	components := make([]Component, 0)
+	signatures := make([]bitset, 0)
+
+	for _, a := range es.componentIndex[componentType] {
+		col := a.index[componentType]
+		refs = append(refs, a.entities...)
+		components = append(components, a.columns[col]...)
+
+		for range a.entities {
+			signatures = append(signatures, a.signature)
+		}
+	}
+
+	cId := es.componentIds[componentType]
+	observers := es.observers
+	subscriptions := es.subscriptions
+	es.readUnlock()
+
+	if len(refs) == 0 {
+		return 0
+	}
+
+	// component hooks
+	for _, c := range components {
+		if hooks, ok := (c).(ComponentWithHooks); ok {
+			hooks.OnDetach()
+		}
+	}
+
+	// system hooks
+	for _, o := range observers {
+		if !o.components.has(cId) {
+			continue
+		}
+
+		if batch, ok := o.observer.(BatchObserver); ok {
+			batch.OnDetachBatch(componentType, toEntities(refs))
+			continue
+		}
+
+		for _, e := range refs {
+			o.observer.OnDetach(componentType, e)
+		}
+	}
+
+	for i, e := range refs {
+		notifyLeft(subscriptions, e, signatures[i], cId)
+	}
+
+	es.writeLock()
+	defer es.writeUnlock()
+
+	for _, e := range refs {
+		es.removeFrom(e.id, componentType)
+	}
+
+	return len(refs)
+}
+
+// Internal, calls observers & component hooks of components attached to multiple entities.
+func (es *EntityStore) notifyAttachedBatch(changes []entityChange) {
+	// attached (not replaced) components grouped by type ID
+	attached := make(map[int][]*entityRef)
+	names := make(map[int]ComponentType)
+	ids := make([][]int, len(changes))
+
+	es.readLock()
+	observers := es.observers
+	subscriptions := es.subscriptions
+
+	for i, change := range changes {
+		ids[i] = es.componentIdsOf(change.components)
+
+		for j, id := range ids[i] {
+			if !change.replaced[j] {
+				names[id] = change.components[j].Type()
+				attached[id] = append(attached[id], change.e)
+			}
+		}
+	}
+
+	es.readUnlock()
+
+	// system hooks
+	for _, o := range observers {
+		batch, isBatch := o.observer.(BatchObserver)
+
+		for _, id := range slices.Sorted(maps.Keys(attached)) {
+			if !o.components.has(id) {
+				continue
+			}
+
+			if isBatch {
+				batch.OnAttachBatch(names[id], toEntities(attached[id]))
+				continue
+			}
+
+			for _, e := range attached[id] {
+				o.observer.OnAttach(names[id], e)
+			}
+		}
+	}
+
+	// component hooks
+	for _, change := range changes {
+		for _, c := range change.components {
+			if hooks, ok := (c).(ComponentWithHooks); ok {
+				hooks.OnAttach(change.e)
+			}
+		}
+	}
+
+	for i, change := range changes {
+		notifyChanged(subscriptions, change, ids[i])
+	}
+}
+
+// Internal, converts entity references to entities.
+func toEntities(refs []*entityRef) []Entity {
+	entities := make([]Entity, len(refs))
+
+	for i, e := range refs {
+		entities[i] = e
+	}
+
+	return entities
+}
@@ -0,0 +1,72 @@
+package core
+
+import "math/bits"
+
+// Internal, a growable set of small non-negative integers, bit i of word i/64 marks i as present.
+// The zero value is an empty set.
+type bitset []uint64
+
+// Internal, returns true if the set contains i, false for negative i.
+func (b bitset) has(i int) bool {
+	w := i >> 6
+	return i >= 0 && w < len(b) && b[w]&(1<<(i&63)) != 0
+}
+
+// Internal, adds i to the set, grows the set if needed.
+func (b *bitset) set(i int) {
+	w := i >> 6
+
+	for w >= len(*b) {
+		*b = append(*b, 0)
+	}
+
+	(*b)[w] |= 1 << (i & 63)
+}
+
+// Internal, removes i from the set.
+func (b bitset) unset(i int) {
+	if w := i >> 6; w < len(b) {
+		b[w] &^= 1 << (i & 63)
+	}
+}
+
+// Internal, returns true if the set contains all members of other.
+func (b bitset) containsAll(other bitset) bool {
+	for w, bits := range other {
+		if bits == 0 {
+			continue
+		}
+
+		if w >= len(b) || b[w]&bits != bits {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Internal, returns true if the sets have a common member.
+func (b bitset) intersects(other bitset) bool {
+	for w := range min(len(b), len(other)) {
+		if b[w]&other[w] != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Internal, returns set members in ascending order.
+func (b bitset) members() []int {
+	members := make([]int, 0)
+
+	for w, word := range b {
+		for word != 0 {
+			i := bits.TrailingZeros64(word)
+			members = append(members, w<<6+i)
+			word &^= 1 << i
+		}
+	}
+
+	return members
+}
@@ -0,0 +1,148 @@
+package core
+
+import "slices"
+
+// Default max removal log entries kept per component type, see EntityStore.SetRemovedLogLimit.
+const DefaultRemovedLogLimit = 4096
+
+// Returns the current world tick. Component changes are stamped with it, ECS advances it after each system call.
+func (es *EntityStore) Tick() uint64 {
+	es.readLock()
+	defer es.readUnlock()
+
+	return es.tick
+}
+
+// Returns the tick when the running system was processed the last time, 0 outside of systems or on the first run.
+// Finder change filters (Added, Changed, Removed) compare against it by default.
+func (es *EntityStore) LastRunTick() uint64 {
+	es.readLock()
+	defer es.readUnlock()
+
+	return es.systemTick
+}
+
+// Marks provided components of an entity as changed at the current tick, missing component types are ignored.
+// Needed when components are mutated through pointers. Returns ErrEntityNotAlive if the entity doesn't exist.
+func (es *EntityStore) MarkChanged(id EntityID, componentTypes ...string) error {
+	es.writeLock()
+	defer es.writeUnlock()
+
+	loc, ok := es.location(id)
+
+	if !ok {
+		return notAliveError(id)
+	}
+
+	for _, cType := range componentTypes {
+		if col, ok := loc.arch.index[cType]; ok {
+			loc.arch.ticks[col][loc.row].changed = es.tick
+		}
+	}
+
+	return nil
+}
+
+// Returns IDs of entities whose component of provided type was removed after the since tick.
+// Also contains IDs of removed entities. The log is kept until all running systems have processed it,
+// but at most the newest SetRemovedLogLimit entries per type are returned.
+func (es *EntityStore) RemovedSince(componentType string, since uint64) []EntityID {
+	es.readLock()
+	defer es.readUnlock()
+
+	return es.removedSince(componentType, since)
+}
+
+// Sets max removal log entries kept per component type, n <= 0 disables the limit.
+// The limit bounds the log of standalone stores & removals not processed by systems yet. DefaultRemovedLogLimit by default.
+func (es *EntityStore) SetRemovedLogLimit(n int) {
+	es.writeLock()
+	defer es.writeUnlock()
+
+	es.removedLimit = max(n, 0)
+
+	for cType := range es.removed {
+		es.capRemoved(cType, es.removedLimit)
+	}
+}
+
+// Drops the whole removal log, e.g. after handling RemovedSince results in a standalone store.
+func (es *EntityStore) ClearRemoved() {
+	es.writeLock()
+	defer es.writeUnlock()
+
+	clear(es.removed)
+}
+
+// Internal, returns IDs of entities with the component removed after the since tick without locking.
+func (es *EntityStore) removedSince(componentType string, since uint64) []EntityID {
+	ids := make([]EntityID, 0)
+	log := es.removed[componentType]
+
+	// the log is capped lazily, so it may hold up to twice the limit
+	if es.removedLimit > 0 && len(log) > es.removedLimit {
+		log = log[len(log)-es.removedLimit:]
+	}
+
+	seen := make(map[EntityID]struct{})
+
+	for _, r := range log {
+		if _, ok := seen[r.id]; r.tick > since && !ok {
+			seen[r.id] = struct{}{}
+			ids = append(ids, r.id)
+		}
+	}
+
+	return ids
+}
+
+// Internal, returns true if the component was attached after the since tick.
+func (a *archetype) addedSince(row int, componentType ComponentType, since uint64) bool {
+	t, ok := a.getTicks(row, componentType)
+	return ok && t.added > since
+}
+
+// Internal, returns true if the component was attached or changed after the since tick.
+func (a *archetype) changedSince(row int, componentType ComponentType, since uint64) bool {
+	t, ok := a.getTicks(row, componentType)
+	return ok && t.changed > since
+}
+
+// Internal, appends a removal log entry, keeps at most twice the limit entries, so trimming is amortized.
+func (es *EntityStore) logRemoved(componentType ComponentType, id EntityID) {
+	es.removed[componentType] = append(es.removed[componentType], _RemovedComponent{id: id, tick: es.tick})
+
+	if es.removedLimit > 0 && len(es.removed[componentType]) >= 2*es.removedLimit {
+		es.capRemoved(componentType, es.removedLimit)
+	}
+}
+
+// Internal, keeps the newest n removal log entries of the type.
+func (es *EntityStore) capRemoved(componentType ComponentType, n int) {
+	log := es.removed[componentType]
+
+	if n <= 0 || len(log) <= n {
+		return
+	}
+
+	copy(log, log[len(log)-n:])
+	es.removed[componentType] = log[:n]
+}
+
+// Internal, drops removal log entries seen by all systems (stamped at or before provided tick).
+func (es *EntityStore) trimRemoved(tick uint64) {
+	es.writeLock()
+	defer es.writeUnlock()
+
+	for cType, log := range es.removed {
+		log = slices.DeleteFunc(log, func(r _RemovedComponent) bool {
+			return r.tick <= tick
+		})
+
+		if len(log) == 0 {
+			delete(es.removed, cType)
+		} else {
+			es.removed[cType] = log
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// Time source used by ECS & SystemStore, replace it to control time in tests & replays.
+type Clock interface {
+	// Returns the current time.
+	Now() time.Time
+}
+
+// Clock implementation that returns the real (wall-clock) time.
+type RealClock struct{}
+
+// Returns the real current time.
+func (c RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// Clock implementation that changes only when advanced explicitly.
+type ManualClock struct {
+	now  time.Time
+	lock sync.Mutex
+}
+
+// Manual clock constructor, starts at the provided time.
+func MakeManualClock(start time.Time) *ManualClock {
+	return &ManualClock{
+		now: start,
+	}
+}
+
+// Returns the current manual time.
+func (c *ManualClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.now
+}
+
+// Moves the clock forward by provided duration.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// Sets the current manual time.
+func (c *ManualClock) Set(now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.now = now
+}
@@ -0,0 +1,141 @@
+package core
+
+import "reflect"
+
+// Component that knows how to copy itself, used instead of the reflection deep copy.
+// Implement it for components with unexported state or resources that shouldn't be copied.
+type Cloneable interface {
+	Component
+
+	// Returns an independent copy of the component.
+	Clone() Component
+}
+
+// Returns a deep copy of the component: Cloneable components copy themselves, others are copied with reflection.
+// With reflection pointers, slices, maps, arrays & interfaces are copied recursively, shared pointers stay shared in the copy.
+// Unexported fields can't be set with reflection, they are copied shallowly.
+func CloneComponent(c Component) Component {
+	if c == nil {
+		return nil
+	}
+
+	if cl, ok := c.(Cloneable); ok {
+		return cl.Clone()
+	}
+
+	visited := make(map[_VisitedPointer]reflect.Value)
+	return deepCopy(reflect.ValueOf(c), visited).Interface().(Component)
+}
+
+// Creates a new entity with copies of all components of the entity (see CloneComponent).
+// Components are attached with AddTo, so component hooks & observers are called. Tags are copied, relations are not.
+// Returns ErrEntityNotAlive if the entity doesn't exist.
+func (es *EntityStore) Duplicate(id EntityID) (Entity, error) {
+	e := es.lookup(id)
+
+	if e == nil {
+		return nil, notAliveError(id)
+	}
+
+	components := e.GetAll()
+
+	for i, c := range components {
+		components[i] = CloneComponent(c)
+	}
+
+	dup := es.New(components...)
+	es.Tag(dup.Id(), es.Tags(id)...)
+
+	return dup, nil
+}
+
+// Internal, a copied pointer key. The type is needed since a struct & its first field share the address.
+type _VisitedPointer struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// Internal, recursively copies a value, visited maps source pointers to their copies.
+// Pointers into other copied values (e.g. to a struct field) get separate copies.
+func deepCopy(src reflect.Value, visited map[_VisitedPointer]reflect.Value) reflect.Value {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return src
+		}
+
+		key := _VisitedPointer{ptr: src.Pointer(), typ: src.Type()}
+
+		if dst, ok := visited[key]; ok {
+			return dst
+		}
+
+		dst := reflect.New(src.Type().Elem())
+		visited[key] = dst
+		dst.Elem().Set(deepCopy(src.Elem(), visited))
+
+		return dst
+
+	case reflect.Struct:
+		dst := reflect.New(src.Type()).Elem()
+
+		// unexported fields are copied as is
+		dst.Set(src)
+
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				dst.Field(i).Set(deepCopy(src.Field(i), visited))
+			}
+		}
+
+		return dst
+
+	case reflect.Slice:
+		if src.IsNil() {
+			return src
+		}
+
+		dst := reflect.MakeSlice(src.Type(), src.Len(), src.Cap())
+
+		for i := 0; i < src.Len(); i++ {
+			dst.Index(i).Set(deepCopy(src.Index(i), visited))
+		}
+
+		return dst
+
+	case reflect.Array:
+		dst := reflect.New(src.Type()).Elem()
+
+		for i := 0; i < src.Len(); i++ {
+			dst.Index(i).Set(deepCopy(src.Index(i), visited))
+		}
+
+		return dst
+
+	case reflect.Map:
+		if src.IsNil() {
+			return src
+		}
+
+		dst := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+
+		for iter.Next() {
+			dst.SetMapIndex(deepCopy(iter.Key(), visited), deepCopy(iter.Value(), visited))
+		}
+
+		return dst
+
+	case reflect.Interface:
+		if src.IsNil() {
+			return src
+		}
+
+		dst := reflect.New(src.Type()).Elem()
+		dst.Set(deepCopy(src.Elem(), visited))
+
+		return dst
+	}
+
+	return src
+}
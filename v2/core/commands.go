@@ -0,0 +1,186 @@
+package core
+
+import (
+	"errors"
+	"sync"
+)
+
+// Internal command kinds recorded by the CommandBuffer.
+type _CommandKind int
+
+const (
+	_CommandNew _CommandKind = iota
+	_CommandRemove
+	_CommandAddTo
+	_CommandRemoveFrom
+	_CommandTag
+	_CommandUntag
+)
+
+// Internal, a single recorded structural change.
+type _Command struct {
+	kind       _CommandKind
+	id         EntityID
+	components []Component
+	types      []string
+}
+
+// Records structural changes (entity creation & removal, component attachment & detachment) to apply them later.
+// Use it inside systems, observers & query iterations, where changing the store directly is unsafe.
+// Recording is safe from multiple goroutines, the order between goroutines is not defined.
+type CommandBuffer struct {
+	es       *EntityStore
+	commands []_Command
+	lock     sync.Mutex
+}
+
+// Command buffer constructor.
+func MakeCommandBuffer(es *EntityStore) *CommandBuffer {
+	return &CommandBuffer{
+		es:       es,
+		commands: make([]_Command, 0),
+	}
+}
+
+// Records entity creation, returns reserved entity ID. The entity exists only after Apply.
+func (cb *CommandBuffer) New(components ...Component) EntityID {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	id := cb.es.reserveId()
+
+	cb.commands = append(cb.commands, _Command{
+		kind:       _CommandNew,
+		id:         id,
+		components: components,
+	})
+
+	return id
+}
+
+// Records entity removal.
+func (cb *CommandBuffer) Remove(id EntityID) {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	cb.commands = append(cb.commands, _Command{
+		kind: _CommandRemove,
+		id:   id,
+	})
+}
+
+// Records components attachment.
+func (cb *CommandBuffer) AddTo(id EntityID, components ...Component) {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	cb.commands = append(cb.commands, _Command{
+		kind:       _CommandAddTo,
+		id:         id,
+		components: components,
+	})
+}
+
+// Records components detachment.
+func (cb *CommandBuffer) RemoveFrom(id EntityID, componentTypes ...string) {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	cb.commands = append(cb.commands, _Command{
+		kind:  _CommandRemoveFrom,
+		id:    id,
+		types: componentTypes,
+	})
+}
+
+// Records tags attachment.
+func (cb *CommandBuffer) Tag(id EntityID, tags ...string) {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	cb.commands = append(cb.commands, _Command{
+		kind:  _CommandTag,
+		id:    id,
+		types: tags,
+	})
+}
+
+// Records tags detachment.
+func (cb *CommandBuffer) Untag(id EntityID, tags ...string) {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	cb.commands = append(cb.commands, _Command{
+		kind:  _CommandUntag,
+		id:    id,
+		types: tags,
+	})
+}
+
+// Returns recorded commands count.
+func (cb *CommandBuffer) Len() int {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	return len(cb.commands)
+}
+
+// Drops all recorded commands, releases entity IDs reserved by New.
+func (cb *CommandBuffer) Clear() {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	cb.es.writeLock()
+	defer cb.es.writeUnlock()
+
+	for _, c := range cb.commands {
+		if c.kind == _CommandNew {
+			cb.es.releaseId(c.id)
+		}
+	}
+
+	cb.commands = cb.commands[:0]
+}
+
+// Applies all recorded commands in the recording order and clears the buffer.
+// Commands recorded while applying (e.g. by hooks) are applied in the same call.
+// Failed commands (e.g. targeting removed entities) are skipped, their errors are joined & returned.
+func (cb *CommandBuffer) Apply() error {
+	errs := make([]error, 0)
+
+	for {
+		cb.lock.Lock()
+		commands := cb.commands
+		cb.commands = make([]_Command, 0)
+		cb.lock.Unlock()
+
+		if len(commands) == 0 {
+			break
+		}
+
+		for _, c := range commands {
+			var err error
+
+			switch c.kind {
+			case _CommandNew:
+				cb.es.spawn(c.id, c.components...)
+			case _CommandRemove:
+				err = cb.es.Remove(c.id)
+			case _CommandAddTo:
+				err = cb.es.AddTo(c.id, c.components...)
+			case _CommandRemoveFrom:
+				err = cb.es.RemoveFrom(c.id, c.types...)
+			case _CommandTag:
+				err = cb.es.Tag(c.id, c.types...)
+			case _CommandUntag:
+				err = cb.es.Untag(c.id, c.types...)
+			}
+
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,49 @@
+package core
+
+import "sync"
+
+// Enables or disables the concurrent mode. In the concurrent mode store, entity, query & finder methods are guarded
+// by a read-write lock, so the store can be used from other goroutines (e.g. networking) while ECS processes systems.
+// Component hooks, observers, query & finder callbacks are called without holding the lock, so they may use the store.
+// Components themselves are not guarded, synchronize access to their fields yourself.
+// Should be called before the store is shared between goroutines.
+func (es *EntityStore) SetConcurrent(enabled bool) {
+	if !enabled {
+		es.lock = nil
+	} else if es.lock == nil {
+		es.lock = &sync.RWMutex{}
+	}
+}
+
+// Returns true if the concurrent mode is enabled.
+func (es *EntityStore) Concurrent() bool {
+	return es.lock != nil
+}
+
+// Internal, locks the store for reading in the concurrent mode.
+func (es *EntityStore) readLock() {
+	if es.lock != nil {
+		es.lock.RLock()
+	}
+}
+
+// Internal, unlocks the store locked for reading.
+func (es *EntityStore) readUnlock() {
+	if es.lock != nil {
+		es.lock.RUnlock()
+	}
+}
+
+// Internal, locks the store for writing in the concurrent mode.
+func (es *EntityStore) writeLock() {
+	if es.lock != nil {
+		es.lock.Lock()
+	}
+}
+
+// Internal, unlocks the store locked for writing.
+func (es *EntityStore) writeUnlock() {
+	if es.lock != nil {
+		es.lock.Unlock()
+	}
+}
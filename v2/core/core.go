@@ -1,50 +1,235 @@
 package core
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// Defines when ECS applies the EntityStore command buffer.
+type SyncPoint int
+
+const (
+	// Commands are applied after each system call.
+	SyncAfterSystem SyncPoint = iota
+	// Commands are applied once at the end of the frame.
+	SyncAfterFrame
+)
 
 // Core of the ECS engine.
 type ECS struct {
 	EntityStore EntityStore
 	SystemStore SystemStore
+
+	// Typed events bus for messaging between systems, flushed at the start of each frame.
+	Events *EventBus
+
+	// Typed singleton world data, accessible from systems with EntityStore.World().
+	Resources *Resources
+
+	// Application state machine, queued transitions are applied at the start of each frame.
+	States *StateMachine
+
+	// When to apply deferred structural changes (EntityStore.Commands), SyncAfterSystem by default.
+	// With parallel workers commands are applied after each batch of systems.
+	SyncPoint SyncPoint
+
+	// Max systems processed at the same time, 0 or 1 processes systems one by one (default).
+	// Only systems implementing SystemWithAccess with non-conflicting access are processed in parallel,
+	// they must not change the store directly, use EntityStore.Commands instead.
+	Workers int
+
+	fixedStep _FixedStep
 }
 
 // Creates a new ECS instance.
 func MakeECS() *ECS {
-	return &ECS{
+	e := &ECS{
 		EntityStore: *MakeEntityStore(),
 		SystemStore: *MakeSystemStore(),
+		Events:      MakeEventBus(),
+		Resources:   MakeResources(),
+		States:      MakeStateMachine(),
+		SyncPoint:   SyncAfterSystem,
 	}
+
+	// the store was copied, bind its command buffer to the copy
+	e.EntityStore.commands = MakeCommandBuffer(&e.EntityStore)
+	e.EntityStore.world = e
+
+	return e
+}
+
+// Sets the clock used for system frequencies & the fixed step mode, also used by the SystemStore.
+// Last call times of already added systems are reset to the new clock time.
+func (e *ECS) SetClock(clock Clock) {
+	e.SystemStore.SetClock(clock)
+	e.fixedStep.lastProcess = time.Time{}
 }
 
-// Runs all systems Setup method considering their priority.
-func (e *ECS) Setup() {
+// Returns the clock used by ECS.
+func (e *ECS) Clock() Clock {
+	return e.SystemStore.clock
+}
+
+// Returns system types in the execution order: by stage, ordering constraints & priority. May be useful for debugging.
+func (e *ECS) ExecutionOrder() []string {
+	order := make([]string, len(e.SystemStore.priority))
+
+	for i, p := range e.SystemStore.priority {
+		order[i] = p.system
+	}
+
+	return order
+}
+
+// Runs all systems Setup method in the execution order.
+// Returns joined errors of failed deferred commands (see CommandBuffer.Apply), systems are run anyway.
+func (e *ECS) Setup() error {
+	errs := make([]error, 0)
+
 	for _, p := range e.SystemStore.priority {
 		s := e.SystemStore.systems[p.system]
 		s.Setup(&e.EntityStore)
+		errs = append(errs, e.syncSystem())
 	}
+
+	errs = append(errs, e.EntityStore.Commands().Apply())
+	return errors.Join(errs...)
 }
 
-// Runs all systems Process method considering their frequency and priority.
-func (e *ECS) Process() {
-	now := time.Now()
+// Runs all systems Process method considering their frequency and execution order (see ExecutionOrder), flushes events before systems.
+// Disabled systems & systems with failed run conditions are skipped, the queued state transition is applied first.
+// With Workers > 1 non-conflicting systems are processed in parallel, conflicting ones keep the priority order.
+// In the fixed step mode (see SetFixedStep) runs as many fixed steps as the elapsed time requires.
+// Returns joined errors of failed deferred commands (see CommandBuffer.Apply), the frame is processed anyway.
+func (e *ECS) Process() error {
+	if e.fixedStep.step > 0 {
+		return e.processFixed()
+	}
+
+	errs := make([]error, 0)
+	errs = append(errs, e.applyStateTransition())
+	e.Events.Flush()
+
+	now := e.SystemStore.clock.Now()
 	systems := e.SystemStore.GetAll()
 	callTime := e.SystemStore.LastCallTimeMap()
 
+	due := make([]System, 0, len(systems))
+	elapsed := make(map[string]time.Duration, len(systems))
+
 	for _, p := range e.SystemStore.Priority() {
 		s := systems[p.system]
-		elapsed := now.Sub(callTime[p.system])
+		sElapsed := now.Sub(callTime[p.system])
 
-		if elapsed >= (time.Duration(s.Frequency()) * time.Millisecond) {
-			s.Process(&e.EntityStore, elapsed)
-			callTime[p.system] = now
+		if sElapsed >= (time.Duration(s.Frequency()) * time.Millisecond) {
+			due = append(due, s)
+			elapsed[p.system] = sElapsed
 		}
 	}
+
+	// skipped systems are considered called, so they don't get a huge dt after resuming
+	errs = append(errs, e.processSystems(due, elapsed))
+
+	for _, s := range due {
+		callTime[s.Type()] = now
+	}
+
+	errs = append(errs, e.EntityStore.Commands().Apply())
+	return errors.Join(errs...)
 }
 
-// Runs all systems Cleanup method considering their priority.
-func (e *ECS) Cleanup() {
+// Runs all systems Cleanup method in the execution order.
+// Returns joined errors of failed deferred commands (see CommandBuffer.Apply), systems are run anyway.
+func (e *ECS) Cleanup() error {
+	errs := make([]error, 0)
+
 	for _, p := range e.SystemStore.priority {
 		s := e.SystemStore.systems[p.system]
 		s.Cleanup(&e.EntityStore)
+		errs = append(errs, e.syncSystem())
 	}
+
+	errs = append(errs, e.EntityStore.Commands().Apply())
+	return errors.Join(errs...)
+}
+
+// Internal, calls Process of provided systems in order, in parallel batches if workers are enabled.
+// Run conditions are evaluated right before each system (batch), so they see changes of previous systems.
+// The world tick is advanced after each system (batch), the removal log is trimmed at the end.
+// Returns joined errors of deferred commands applied between systems.
+func (e *ECS) processSystems(systems []System, elapsed map[string]time.Duration) error {
+	errs := make([]error, 0)
+
+	if e.Workers <= 1 {
+		for _, s := range systems {
+			if !e.shouldRunDue(s) {
+				continue
+			}
+
+			e.beginTick(s)
+			s.Process(&e.EntityStore, elapsed[s.Type()])
+			errs = append(errs, e.syncSystem())
+			e.endTick(s)
+		}
+	} else {
+		for _, batch := range makeSystemBatches(systems) {
+			batch = e.runnable(batch)
+
+			if len(batch) == 0 {
+				continue
+			}
+
+			// systems of a batch share the store, so the oldest last run tick is used
+			e.beginTick(batch...)
+
+			runOnWorkers(batch, e.Workers, func(s System) {
+				s.Process(&e.EntityStore, elapsed[s.Type()])
+			})
+
+			errs = append(errs, e.syncSystem())
+			e.endTick(batch...)
+		}
+	}
+
+	es := &e.EntityStore
+	es.trimRemoved(e.SystemStore.minLastRunTick(es.Tick()))
+
+	return errors.Join(errs...)
+}
+
+// Internal, exposes the lowest last run tick of provided systems to the store.
+func (e *ECS) beginTick(systems ...System) {
+	since := e.EntityStore.tick
+
+	for _, s := range systems {
+		since = min(since, e.SystemStore.lastRunTick[s.Type()])
+	}
+
+	e.EntityStore.writeLock()
+	e.EntityStore.systemTick = since
+	e.EntityStore.writeUnlock()
+}
+
+// Internal, stores last run ticks of provided systems & advances the world tick.
+func (e *ECS) endTick(systems ...System) {
+	es := &e.EntityStore
+	es.writeLock()
+	defer es.writeUnlock()
+
+	for _, s := range systems {
+		e.SystemStore.lastRunTick[s.Type()] = es.tick
+	}
+
+	es.systemTick = 0
+	es.tick++
+}
+
+// Internal, applies deferred commands after a system call if configured, returns errors of failed commands.
+func (e *ECS) syncSystem() error {
+	if e.SyncPoint == SyncAfterSystem {
+		return e.EntityStore.Commands().Apply()
+	}
+
+	return nil
 }
@@ -1,14 +1,31 @@
 package core
 
+// Entity ID, packs a slot index (low 32 bits) and a generation (high 32 bits).
+// The slot generation is incremented on removal, so stale IDs never match a reused slot.
 type EntityID uint64
 
+// Creates an entity ID from slot index & generation.
+func MakeEntityID(index uint32, generation uint32) EntityID {
+	return EntityID(uint64(generation)<<32 | uint64(index))
+}
+
+// Returns entity slot index.
+func (id EntityID) Index() uint32 {
+	return uint32(id)
+}
+
+// Returns entity slot generation.
+func (id EntityID) Generation() uint32 {
+	return uint32(id >> 32)
+}
+
 // Internal entity implementation struct, should not be used directly.
 type entityRef struct {
 	id EntityID
 	es *EntityStore
 }
 
-// Entity is just an interface, all data is stored in EntityStore as maps for performance reasons.
+// Entity is just an interface, all data is stored in EntityStore archetypes for performance reasons.
 type Entity interface {
 	// Returns entity ID (uint64).
 	Id() EntityID
@@ -19,27 +36,37 @@ type Entity interface {
 	// Returns a component with provided type attached to the entity, may return nil if no such component exists.
 	GetOne(componentType string) *Component
 
+	// Same as GetOne, but also marks the component as changed for change detection.
+	GetMut(componentType string) *Component
+
 	// Returns a list of components attached to the entity with provided types.
 	GetList(componentTypes ...string) []Component
 
 	// Returns a list of all components attached to the entity.
 	GetAll() []Component
 
-	// Attaches provided components to the entity.
-	Add(components ...Component)
+	// Attaches provided components to the entity. Returns ErrEntityNotAlive if the entity was removed.
+	Add(components ...Component) error
+
+	// Detaches provided component types from the entity. Returns ErrEntityNotAlive if the entity was removed.
+	Remove(componentTypes ...string) error
+
+	// Attaches provided tags to the entity. Returns ErrEntityNotAlive if the entity was removed.
+	Tag(tags ...string) error
 
-	// Detaches provided component types from the entity.
-	Remove(componentTypes ...string)
+	// Detaches provided tags from the entity. Returns ErrEntityNotAlive if the entity was removed.
+	Untag(tags ...string) error
+
+	// Returns true if entity has all provided tags.
+	HasTag(tags ...string) bool
 }
 
 // A handy internal constructor
-func makeEntity(id EntityID, es *EntityStore) Entity {
-	e := entityRef{
+func makeEntity(id EntityID, es *EntityStore) *entityRef {
+	return &entityRef{
 		id: id,
 		es: es,
 	}
-
-	return &e
 }
 
 // Returns entity ID (uint64).
@@ -49,31 +76,64 @@ func (e *entityRef) Id() EntityID {
 
 // Returns true if entity has all provided components types attached to it.
 func (e *entityRef) Has(componentTypes ...string) bool {
-	comps := e.GetList(componentTypes...)
+	e.es.readLock()
+	defer e.es.readUnlock()
 
-	return len(comps) == len(componentTypes)
+	loc, ok := e.es.location(e.id)
+
+	if !ok {
+		return false
+	}
+
+	return e.es.signatureHas(loc.arch, componentTypes)
 }
 
 // Returns an attached component by provided type, may return nil if no such component exists.
 func (e *entityRef) GetOne(componentType string) *Component {
-	for _, c := range e.es.ec_map[e.id] {
-		if c.Type() == componentType {
-			return &c
-		}
+	e.es.readLock()
+	defer e.es.readUnlock()
+
+	loc, ok := e.es.location(e.id)
+
+	if !ok {
+		return nil
 	}
 
-	return nil
+	c := loc.arch.get(loc.row, componentType)
+
+	if c == nil {
+		return nil
+	}
+
+	return &c
+}
+
+// Returns an attached component by provided type & marks it as changed, may return nil if no such component exists.
+func (e *entityRef) GetMut(componentType string) *Component {
+	c := e.GetOne(componentType)
+
+	if c != nil {
+		e.es.MarkChanged(e.id, componentType)
+	}
+
+	return c
 }
 
 // Returns a list of components attached to the entity with provided types.
 func (e *entityRef) GetList(componentTypes ...string) []Component {
+	e.es.readLock()
+	defer e.es.readUnlock()
+
 	comps := make([]Component, 0)
+	loc, ok := e.es.location(e.id)
+
+	if !ok {
+		return comps
+	}
 
-	for _, c := range e.es.ec_map[e.id] {
-		for _, ct := range componentTypes {
-			if c.Type() == ct {
-				comps = append(comps, c)
-			}
+	for _, ct := range componentTypes {
+		if c := loc.arch.get(loc.row, ct); c != nil {
+			comps = append(comps, c)
 		}
 	}
 
@@ -82,21 +142,50 @@ func (e *entityRef) GetList(componentTypes ...string) []Component {
 
 // Returns a list of all components attached to the entity.
 func (e *entityRef) GetAll() []Component {
-	comps := make([]Component, 0)
+	e.es.readLock()
+	defer e.es.readUnlock()
+
+	loc, ok := e.es.location(e.id)
 
-	for _, c := range e.es.ec_map[e.id] {
-		comps = append(comps, c)
+	if !ok {
+		return make([]Component, 0)
 	}
 
-	return comps
+	return e.getAll(loc)
 }
 
 // Attaches provided components to the entity.
-func (e *entityRef) Add(components ...Component) {
-	e.es.AddTo(e.id, components...)
+func (e *entityRef) Add(components ...Component) error {
+	return e.es.AddTo(e.id, components...)
 }
 
 // Detaches provided component types from the entity.
-func (e *entityRef) Remove(componentTypes ...string) {
-	e.es.RemoveFrom(e.id, componentTypes...)
+func (e *entityRef) Remove(componentTypes ...string) error {
+	return e.es.RemoveFrom(e.id, componentTypes...)
+}
+
+// Attaches provided tags to the entity.
+func (e *entityRef) Tag(tags ...string) error {
+	return e.es.Tag(e.id, tags...)
+}
+
+// Detaches provided tags from the entity.
+func (e *entityRef) Untag(tags ...string) error {
+	return e.es.Untag(e.id, tags...)
+}
+
+// Returns true if entity has all provided tags.
+func (e *entityRef) HasTag(tags ...string) bool {
+	return e.es.HasTag(e.id, tags...)
+}
+
+// Internal, returns all components stored at the entity location.
+func (e *entityRef) getAll(loc entityLocation) []Component {
+	comps := make([]Component, 0, len(loc.arch.columns))
+
+	for _, col := range loc.arch.columns {
+		comps = append(comps, col[loc.row])
+	}
+
+	return comps
 }
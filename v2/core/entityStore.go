@@ -1,161 +1,719 @@
 package core
 
 import (
+	"errors"
+	"fmt"
 	"slices"
+	"sync"
 
 	"github.com/kostayne/ecs/v2/utils"
 )
 
 type ComponentType = string
 
-// Component to Entity map, needed for lookup by component type.
-type CE_Map = map[ComponentType]map[EntityID]Component
+// Returned by operations on a removed (stale) or never created entity.
+var ErrEntityNotAlive = errors.New("entity is not alive")
 
-// Entity to Component map, needed for lookup by entity ID.
-type EC_Map = map[EntityID]map[ComponentType]Component
+// Internal, entity slot state, slots are reused after removal with incremented generation.
+type entitySlot struct {
+	generation uint32
+	alive      bool
+	loc        entityLocation
 
-// Internal, needed for storing empty entities.
-type _EntityMap = map[EntityID]Entity
+	// Attached tags, bits are tag IDs (see EntityStore.Tag).
+	tags bitset
+}
+
+// Internal, removed component log entry.
+type _RemovedComponent struct {
+	id   EntityID
+	tick uint64
+}
 
 // Stores entities and provides convenient management methods.
+// Entities are grouped by their exact component set (archetype), components of an archetype are stored in columns.
 type EntityStore struct {
+	// Slots count, the next fresh slot index.
 	maxId EntityID
 
-	ce_map CE_Map
-	ec_map EC_Map
-
-	observers []Observer
-	entities  map[EntityID]Entity
+	// Entity slots by index, freed slot indices are reused.
+	slots    []entitySlot
+	freeList []uint32
+	alive    int
+
+	// Archetypes in creation order, the first one is the root (no components).
+	archetypes     []*archetype
+	archetypeIndex map[string]*archetype
+
+	// Component type to archetypes containing it, needed for lookup by component type.
+	componentIndex map[ComponentType][]*archetype
+
+	// Component type ID by type & types by ID, IDs are assigned on first use (see ComponentID).
+	componentIds  map[ComponentType]int
+	componentList []ComponentType
+
+	// Registered queries by their types key, updated when a new archetype is created.
+	queries map[string]*Query
+	// Guards queries registration, systems may look up queries in parallel.
+	queryLock *sync.RWMutex
+
+	// Parent of an entity & children of an entity in the attachment order.
+	parents  map[EntityID]EntityID
+	children map[EntityID][]EntityID
+
+	// Guards the store in the concurrent mode, nil otherwise (see SetConcurrent).
+	lock *sync.RWMutex
+
+	// Deferred structural changes, applied by ECS at sync points.
+	commands *CommandBuffer
+
+	// ECS owning the store, nil for standalone stores.
+	world *ECS
+
+	// Current world tick, component changes are stamped with it. Advanced by ECS after each system.
+	tick uint64
+	// Last run tick of the running system, the default Finder change detection threshold.
+	systemTick uint64
+	// Removed components log by type, trimmed when all systems have seen the removal.
+	removed map[ComponentType][]_RemovedComponent
+	// Max removal log entries kept per type, 0 means no limit (see SetRemovedLogLimit).
+	removedLimit int
+
+	// Registered prefabs by name.
+	prefabs map[string]*Prefab
+
+	// Tag bit by name & tag names by bit, tags are registered on first use.
+	tagIds  map[string]int
+	tagList []string
+
+	// Added observers & query observer subscriptions, copied on write, so hooks can iterate the previous lists unlocked.
+	observers     []observerEntry
+	subscriptions []subscription
+	// The last observer handle ID.
+	observerId uint64
 }
 
 // Entity store constructor.
 func MakeEntityStore() *EntityStore {
-	return &EntityStore{
+	es := &EntityStore{
 		maxId: 0,
 
-		ce_map: make(CE_Map),
-		ec_map: make(EC_Map),
+		slots:    make([]entitySlot, 0),
+		freeList: make([]uint32, 0),
+
+		archetypes:     make([]*archetype, 0),
+		archetypeIndex: make(map[string]*archetype),
+		componentIndex: make(map[ComponentType][]*archetype),
+		componentIds:   make(map[ComponentType]int),
+		componentList:  make([]ComponentType, 0),
+		queries:        make(map[string]*Query),
+		queryLock:      &sync.RWMutex{},
+
+		parents:  make(map[EntityID]EntityID),
+		children: make(map[EntityID][]EntityID),
+
+		tick:         1,
+		removed:      make(map[ComponentType][]_RemovedComponent),
+		removedLimit: DefaultRemovedLogLimit,
+		prefabs:      make(map[string]*Prefab),
 
-		entities:  make(_EntityMap),
-		observers: make([]Observer, 0),
+		tagIds:  make(map[string]int),
+		tagList: make([]string, 0),
+
+		observers:     make([]observerEntry, 0),
+		subscriptions: make([]subscription, 0),
 	}
+
+	es.commands = MakeCommandBuffer(es)
+	es.getArchetype([]ComponentType{})
+
+	return es
 }
 
 // Creates a new entity and attaches provided components to it.
 func (es *EntityStore) New(components ...Component) Entity {
-	for _, c := range components {
-		es.AddTo(es.maxId, c)
+	return es.spawn(es.reserveId(), components...)
+}
+
+// Returns the store command buffer. ECS applies it after each system or at the end of the frame (see ECS.SyncPoint).
+// Safe to use from systems running in parallel.
+func (es *EntityStore) Commands() *CommandBuffer {
+	return es.commands
+}
+
+// Returns the ECS owning the store, so systems can reach resources & events. Returns nil for standalone stores.
+func (es *EntityStore) World() *ECS {
+	return es.world
+}
+
+// Returns true if the entity exists, false for removed (stale) or never created IDs.
+func (es *EntityStore) Alive(id EntityID) bool {
+	es.readLock()
+	defer es.readUnlock()
+
+	_, ok := es.location(id)
+	return ok
+}
+
+// Removes an entity by entity id, also detaches all components & tags from the entity & removes its children.
+// Returns ErrEntityNotAlive if the entity doesn't exist.
+func (es *EntityStore) Remove(id EntityID) error {
+	if !es.Alive(id) {
+		return notAliveError(id)
 	}
 
-	e := makeEntity(es.maxId, es)
-	es.entities[es.maxId] = e
+	es.notifyRemoved(id, _EventDestroy)
 
-	es.maxId++
-	return e
+	for _, child := range es.Children(id) {
+		es.Remove(child)
+	}
+
+	es.readLock()
+	loc, ok := es.location(id)
+
+	var types []ComponentType
+
+	if ok {
+		types = slices.Clone(loc.arch.types)
+	}
+
+	es.readUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	// components stay attached until the row is removed, so the entity doesn't move through archetypes
+	es.notifyDetached(id, types)
+	es.Untag(id, es.Tags(id)...)
+
+	// observers of observed types were notified by notifyDetached, notifies the rest
+	es.notifyRemoved(id, _EventLeave)
+
+	es.writeLock()
+	defer es.writeUnlock()
+
+	// hooks may have already removed the entity
+	loc, ok = es.location(id)
+
+	if !ok {
+		return nil
+	}
+
+	for _, t := range loc.arch.types {
+		es.logRemoved(t, id)
+	}
+
+	es.detachFromParent(id)
+	es.detachRow(loc)
+	es.freeSlot(id)
+
+	return nil
 }
 
-// Removes an entity by entity id, also detaches all components from the entity.
-func (es *EntityStore) Remove(id EntityID) {
-	for cType := range es.ec_map[id] {
-		es.RemoveFrom(id, cType)
+// Attaches components to an entity by ID. Returns ErrEntityNotAlive if the entity doesn't exist.
+func (es *EntityStore) AddTo(id EntityID, components ...Component) error {
+	es.writeLock()
+	change, err := es.addTo(id, components)
+	es.writeUnlock()
 
-		delete(es.ce_map[cType], id)
-		delete(es.ec_map[id], cType)
+	if err != nil {
+		return err
 	}
 
-	delete(es.entities, id)
+	es.notifyAttached(change)
+	return nil
 }
 
-// Attaches components to an entity by ID.
-func (es *EntityStore) AddTo(id EntityID, components ...Component) {
-	for _, c := range components {
-		cType := c.Type()
+// Detaches components from an entity by entity ID. Returns ErrEntityNotAlive if the entity doesn't exist.
+func (es *EntityStore) RemoveFrom(id EntityID, componentTypes ...string) error {
+	if !es.Alive(id) {
+		return notAliveError(id)
+	}
 
-		if es.ce_map[cType] == nil {
-			es.ce_map[cType] = make(map[EntityID]Component)
-		}
+	es.notifyDetached(id, componentTypes)
 
-		if es.ec_map[id] == nil {
-			es.ec_map[id] = make(map[ComponentType]Component)
-		}
+	es.writeLock()
+	es.removeFrom(id, componentTypes...)
+	es.writeUnlock()
 
-		es.ce_map[cType][id] = c
-		es.ec_map[id][cType] = c
+	return nil
+}
 
-		e := makeEntity(id, es)
+// Internal, calls component hooks, observers & leave callbacks of components about to be detached.
+// The store must not be locked, components are still attached while hooks are called.
+func (es *EntityStore) notifyDetached(id EntityID, componentTypes []ComponentType) {
+	// IDs of notified types, they are considered detached by leave callbacks of the next types
+	notified := make([]int, 0, len(componentTypes))
 
-		// system hooks
-		for _, observer := range es.observers {
-			if slices.Contains(observer.GetObservedTypes(), cType) {
-				observer.OnAttach(cType, e)
+	for _, cType := range componentTypes {
+		es.readLock()
+		loc, ok := es.location(id)
+		observers := es.observers
+		subscriptions := es.subscriptions
+
+		var c Component
+		var e *entityRef
+		var signature bitset
+
+		if ok && loc.arch.has(cType) {
+			c = loc.arch.get(loc.row, cType)
+			e = loc.arch.entities[loc.row]
+
+			// only leave callbacks need the signature
+			if len(subscriptions) > 0 {
+				signature = slices.Clone(loc.arch.signature)
 			}
 		}
 
-		// component hooks
-		hooks, ok := (c).(ComponentWithHooks)
+		cId := es.componentIds[cType]
+		es.readUnlock()
 
-		if ok {
-			e := makeEntity(id, es)
-			hooks.OnAttach(e)
+		// missing or repeated type
+		if e == nil || slices.Contains(notified, cId) {
+			continue
 		}
-	}
-}
-
-// Detaches components from an entity by entity ID.
-func (es *EntityStore) RemoveFrom(id EntityID, componentTypes ...string) {
-	for _, cType := range componentTypes {
-		c := es.ce_map[cType][id]
-		e := makeEntity(id, es)
 
 		// component hooks
 		if hooks, ok := (c).(ComponentWithHooks); ok {
 			hooks.OnDetach()
 		}
+
 		// system hooks
-		for _, observer := range es.observers {
-			if slices.Contains(observer.GetObservedTypes(), cType) {
-				observer.OnDetach(cType, e)
+		for _, o := range observers {
+			if o.components.has(cId) {
+				o.observer.OnDetach(cType, e)
 			}
 		}
 
-		delete(es.ce_map[cType], id)
-		delete(es.ec_map[id], cType)
+		for _, n := range notified {
+			signature.unset(n)
+		}
+
+		notifyLeft(subscriptions, e, signature, cId)
+		notified = append(notified, cId)
 	}
 }
 
 // Returns a list of attached components by entity ID.
 func (es *EntityStore) GetById(id EntityID) []Component {
-	e := es.entities[id]
+	es.readLock()
+	defer es.readUnlock()
+
+	loc, ok := es.location(id)
 
-	if e == nil {
+	if !ok {
 		return nil
 	}
 
-	return e.GetAll()
+	return loc.arch.entities[loc.row].getAll(loc)
 }
 
 // Returns a list of all stored entities.
 func (es *EntityStore) GetAll() []Entity {
-	entities := make([]Entity, len(es.entities))
+	es.readLock()
+	defer es.readUnlock()
 
-	for i, e := range es.entities {
-		entities[i] = e
+	entities := make([]Entity, 0, es.alive)
+
+	for _, a := range es.archetypes {
+		for _, e := range a.entities {
+			entities = append(entities, e)
+		}
 	}
 
 	return entities
 }
 
-// Adds an observer to the entity store.
-func (es *EntityStore) AddObserver(observer Observer) {
-	es.observers = append(es.observers, observer)
+// Adds an observer to the entity store, returns a handle to remove it.
+// Observed types are read once, set them before adding the observer.
+// The observer is notified when observed components are attached or detached, not when they are replaced.
+func (es *EntityStore) AddObserver(observer Observer) ObserverHandle {
+	es.writeLock()
+	defer es.writeUnlock()
+
+	es.observerId++
+	entry := es.makeObserverEntry(es.observerId, observer)
+
+	es.observers = append(slices.Clip(es.observers), entry)
+	return ObserverHandle{es: es, id: entry.id}
 }
 
-// Removes an observer from the entity store.
+// Removes an observer from the entity store by comparing observers, prefer ObserverHandle.Unsubscribe.
 func (es *EntityStore) RemoveObserver(observer Observer) {
+	es.writeLock()
+	defer es.writeUnlock()
+
 	for i, o := range es.observers {
-		if o == observer {
-			es.observers = utils.ShiftRemoveI(es.observers, i)
+		if o.observer == observer {
+			es.observers = utils.ShiftRemoveI(slices.Clone(es.observers), i)
 			break
 		}
 	}
 }
+
+// Internal, attaches components to an entity without calling hooks, returns the change to notify about.
+func (es *EntityStore) addTo(id EntityID, components []Component) (entityChange, error) {
+	loc, ok := es.location(id)
+
+	if !ok {
+		return entityChange{}, notAliveError(id)
+	}
+
+	components = uniqueComponents(components)
+
+	change := entityChange{
+		e:          loc.arch.entities[loc.row],
+		before:     loc.arch.signature,
+		after:      loc.arch.signature,
+		components: components,
+		replaced:   make([]bool, len(components)),
+	}
+
+	if len(components) == 0 {
+		return change, nil
+	}
+
+	target := loc.arch
+	missing := make([]ComponentType, 0, len(components))
+
+	for i, c := range components {
+		cType := c.Type()
+		change.replaced[i] = loc.arch.has(cType)
+
+		if !change.replaced[i] && !slices.Contains(missing, cType) {
+			missing = append(missing, cType)
+		}
+	}
+
+	// a single type uses the cached edge, several resolve the final archetype directly
+	if len(missing) == 1 {
+		target = es.addEdge(target, missing[0])
+	} else if len(missing) > 1 {
+		target = es.getArchetype(loc.arch.withTypes(missing...))
+	}
+
+	if target != loc.arch {
+		loc = es.move(loc, target)
+	}
+
+	for i, c := range components {
+		col := target.index[c.Type()]
+		target.columns[col][loc.row] = c
+
+		// replaced components keep their added tick
+		if change.replaced[i] {
+			target.ticks[col][loc.row].changed = es.tick
+		} else {
+			target.ticks[col][loc.row] = _ComponentTicks{added: es.tick, changed: es.tick}
+		}
+	}
+
+	change.after = target.signature
+	return change, nil
+}
+
+// Internal, returns components without repeated types, the last component of a type wins.
+// Returns the same slice if there are no repeated types.
+func uniqueComponents(components []Component) []Component {
+	repeated := false
+
+	for i := 1; i < len(components) && !repeated; i++ {
+		repeated = slices.ContainsFunc(components[:i], func(c Component) bool { return c.Type() == components[i].Type() })
+	}
+
+	if !repeated {
+		return components
+	}
+
+	unique := make([]Component, 0, len(components))
+
+	for i, c := range components {
+		last := !slices.ContainsFunc(components[i+1:], func(next Component) bool { return next.Type() == c.Type() })
+
+		if last {
+			unique = append(unique, c)
+		}
+	}
+
+	return unique
+}
+
+// Internal, calls observers & component hooks of attached components, the store must not be locked.
+func (es *EntityStore) notifyAttached(change entityChange) {
+	es.readLock()
+	observers := es.observers
+	subscriptions := es.subscriptions
+
+	var ids []int
+
+	// without observers there is nothing to match
+	if len(observers) > 0 || len(subscriptions) > 0 {
+		ids = es.componentIdsOf(change.components)
+	}
+
+	es.readUnlock()
+
+	for i, c := range change.components {
+		cType := c.Type()
+
+		// system hooks, replaced components are reported to query observers only
+		for _, o := range observers {
+			if !change.replaced[i] && o.components.has(ids[i]) {
+				o.observer.OnAttach(cType, change.e)
+			}
+		}
+
+		// component hooks
+		if hooks, ok := (c).(ComponentWithHooks); ok {
+			hooks.OnAttach(change.e)
+		}
+	}
+
+	notifyChanged(subscriptions, change, ids)
+}
+
+// Internal, detaches a component from an entity without calling hooks, logs the removal.
+func (es *EntityStore) removeFrom(id EntityID, componentTypes ...ComponentType) {
+	// hooks may have changed the entity location
+	loc, ok := es.location(id)
+
+	if !ok {
+		return
+	}
+
+	present := make([]ComponentType, 0, len(componentTypes))
+
+	for _, t := range componentTypes {
+		if loc.arch.has(t) && !slices.Contains(present, t) {
+			present = append(present, t)
+		}
+	}
+
+	if len(present) == 0 {
+		return
+	}
+
+	// a single type uses the cached edge, several resolve the final archetype directly
+	var target *archetype
+
+	if len(present) == 1 {
+		target = es.removeEdge(loc.arch, present[0])
+	} else {
+		target = es.getArchetype(loc.arch.withoutTypes(present...))
+	}
+
+	es.move(loc, target)
+
+	for _, t := range present {
+		es.logRemoved(t, id)
+	}
+}
+
+// Internal, returns a new entity ID, reuses freed slots first.
+// The slot is not alive until spawned, fresh slots are allocated on spawn, so reading entities is safe meanwhile.
+func (es *EntityStore) reserveId() EntityID {
+	es.writeLock()
+	defer es.writeUnlock()
+
+	return es.nextId()
+}
+
+// Internal, reserveId without locking.
+func (es *EntityStore) nextId() EntityID {
+	if n := len(es.freeList); n != 0 {
+		index := es.freeList[n-1]
+		es.freeList = es.freeList[:n-1]
+
+		return MakeEntityID(index, es.slots[index].generation)
+	}
+
+	index := uint32(es.maxId)
+	es.maxId++
+
+	return MakeEntityID(index, 0)
+}
+
+// Internal, allocates slots up to provided index.
+func (es *EntityStore) ensureSlot(index uint32) {
+	for int(index) >= len(es.slots) {
+		es.slots = append(es.slots, entitySlot{})
+	}
+}
+
+// Internal, marks the entity slot as free & increments its generation.
+func (es *EntityStore) freeSlot(id EntityID) {
+	es.releaseId(id)
+	es.alive--
+}
+
+// Internal, returns a reserved or removed entity slot to the free list.
+func (es *EntityStore) releaseId(id EntityID) {
+	es.ensureSlot(id.Index())
+	slot := &es.slots[id.Index()]
+
+	slot.alive = false
+	slot.loc = entityLocation{}
+	slot.tags = nil
+	slot.generation++
+
+	es.freeList = append(es.freeList, id.Index())
+}
+
+// Internal, returns the entity location if it's alive.
+func (es *EntityStore) location(id EntityID) (entityLocation, bool) {
+	index := id.Index()
+
+	if int(index) >= len(es.slots) {
+		return entityLocation{}, false
+	}
+
+	slot := &es.slots[index]
+
+	if !slot.alive || slot.generation != id.Generation() {
+		return entityLocation{}, false
+	}
+
+	return slot.loc, true
+}
+
+// Internal, updates the location of an alive entity.
+func (es *EntityStore) setLocation(id EntityID, loc entityLocation) {
+	es.slots[id.Index()].loc = loc
+}
+
+// Internal, wraps ErrEntityNotAlive with the entity ID.
+func notAliveError(id EntityID) error {
+	return fmt.Errorf("%w: index %d, generation %d", ErrEntityNotAlive, id.Index(), id.Generation())
+}
+
+// Internal, creates an entity with reserved ID and attaches provided components to it.
+func (es *EntityStore) spawn(id EntityID, components ...Component) Entity {
+	es.writeLock()
+	change := es.spawnRow(id, components)
+	es.writeUnlock()
+
+	es.notifyAttached(change)
+	return change.e
+}
+
+// Internal, creates an entity with reserved ID & attaches components without locking & calling hooks.
+func (es *EntityStore) spawnRow(id EntityID, components []Component) entityChange {
+	e := makeEntity(id, es)
+	root := es.archetypes[0]
+
+	es.ensureSlot(id.Index())
+
+	es.slots[id.Index()] = entitySlot{
+		generation: id.Generation(),
+		alive:      true,
+		loc: entityLocation{
+			arch: root,
+			row:  root.push(e),
+		},
+	}
+
+	es.alive++
+
+	change, _ := es.addTo(id, components)
+	change.created = true
+
+	return change
+}
+
+// Internal, returns a stored entity by ID or nil.
+func (es *EntityStore) entity(id EntityID) Entity {
+	loc, ok := es.location(id)
+
+	if !ok {
+		return nil
+	}
+
+	return loc.arch.entities[loc.row]
+}
+
+// Internal, same as entity, but locks the store for reading.
+func (es *EntityStore) lookup(id EntityID) Entity {
+	es.readLock()
+	defer es.readUnlock()
+
+	return es.entity(id)
+}
+
+// Internal, returns an archetype with provided sorted types, creates it if needed.
+func (es *EntityStore) getArchetype(types []ComponentType) *archetype {
+	key := makeArchetypeKey(types)
+
+	if a, ok := es.archetypeIndex[key]; ok {
+		return a
+	}
+
+	var signature bitset
+
+	for _, t := range types {
+		signature.set(es.componentId(t))
+	}
+
+	a := makeArchetype(types, signature)
+
+	es.archetypes = append(es.archetypes, a)
+	es.archetypeIndex[key] = a
+
+	for _, t := range types {
+		es.componentIndex[t] = append(es.componentIndex[t], a)
+	}
+
+	es.queryLock.RLock()
+	defer es.queryLock.RUnlock()
+
+	for _, q := range es.queries {
+		q.tryMatch(a)
+	}
+
+	return a
+}
+
+// Internal, returns an archetype with added component type using cached edges.
+func (es *EntityStore) addEdge(a *archetype, componentType ComponentType) *archetype {
+	if target, ok := a.addEdges[componentType]; ok {
+		return target
+	}
+
+	target := es.getArchetype(a.withTypes(componentType))
+	a.addEdges[componentType] = target
+	target.removeEdges[componentType] = a
+
+	return target
+}
+
+// Internal, returns an archetype without provided component type using cached edges.
+func (es *EntityStore) removeEdge(a *archetype, componentType ComponentType) *archetype {
+	if target, ok := a.removeEdges[componentType]; ok {
+		return target
+	}
+
+	target := es.getArchetype(a.withoutTypes(componentType))
+	a.removeEdges[componentType] = target
+	target.addEdges[componentType] = a
+
+	return target
+}
+
+// Internal, moves an entity row to the target archetype, copies shared components & their ticks.
+// Types missing in the source row are left empty. Returns the new location.
+func (es *EntityStore) move(loc entityLocation, target *archetype) entityLocation {
+	id := loc.arch.entities[loc.row].id
+	moved := entityLocation{arch: target, row: target.pushFrom(loc.arch, loc.row)}
+
+	es.detachRow(loc)
+	es.setLocation(id, moved)
+
+	return moved
+}
+
+// Internal, removes an entity row from its archetype & fixes the moved entity location.
+func (es *EntityStore) detachRow(loc entityLocation) {
+	if moved := loc.arch.swapRemove(loc.row); moved != nil {
+		es.setLocation(moved.id, loc)
+	}
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// Internal, a subscribed event handler.
+type _EventHandler struct {
+	id   uint64
+	call func(event any)
+}
+
+// Internal, a buffered event.
+type _PostedEvent struct {
+	eventType reflect.Type
+	event     any
+}
+
+// Typed event bus for messaging between systems. Events are keyed by their Go type.
+// Emitted events are delivered immediately, posted events are buffered until the next frame (see Flush).
+// Safe to use from systems running in parallel.
+type EventBus struct {
+	handlers map[reflect.Type][]_EventHandler
+	nextId   uint64
+
+	// Events posted during the current frame in the posting order.
+	pending []_PostedEvent
+	// Events posted during the previous frame, readable with ReadEvents.
+	current map[reflect.Type][]any
+
+	lock sync.Mutex
+}
+
+// Subscription handle returned by SubscribeEvent.
+type EventSubscription struct {
+	bus       *EventBus
+	eventType reflect.Type
+	id        uint64
+}
+
+// Event bus constructor.
+func MakeEventBus() *EventBus {
+	return &EventBus{
+		handlers: make(map[reflect.Type][]_EventHandler),
+		pending:  make([]_PostedEvent, 0),
+		current:  make(map[reflect.Type][]any),
+	}
+}
+
+// Subscribes fn to events of type T, returns a handle to unsubscribe.
+func SubscribeEvent[T any](b *EventBus, fn func(event T)) *EventSubscription {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	t := reflect.TypeFor[T]()
+	b.nextId++
+
+	b.handlers[t] = append(b.handlers[t], _EventHandler{
+		id:   b.nextId,
+		call: func(event any) { fn(event.(T)) },
+	})
+
+	return &EventSubscription{
+		bus:       b,
+		eventType: t,
+		id:        b.nextId,
+	}
+}
+
+// Delivers the event to subscribers of type T immediately.
+func EmitEvent[T any](b *EventBus, event T) {
+	b.lock.Lock()
+	handlers := slices.Clone(b.handlers[reflect.TypeFor[T]()])
+	b.lock.Unlock()
+
+	for _, h := range handlers {
+		h.call(event)
+	}
+}
+
+// Buffers the event until the next frame, then it's delivered to subscribers & readable with ReadEvents.
+func PostEvent[T any](b *EventBus, event T) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.pending = append(b.pending, _PostedEvent{
+		eventType: reflect.TypeFor[T](),
+		event:     event,
+	})
+}
+
+// Returns events of type T posted during the previous frame.
+func ReadEvents[T any](b *EventBus) []T {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	queue := b.current[reflect.TypeFor[T]()]
+	events := make([]T, 0, len(queue))
+
+	for _, e := range queue {
+		events = append(events, e.(T))
+	}
+
+	return events
+}
+
+// Starts a new frame: events of the previous frame are dropped, posted events become readable & are delivered to subscribers in the posting order.
+// Called by ECS at the start of each frame.
+func (b *EventBus) Flush() {
+	b.lock.Lock()
+
+	posted := b.pending
+	b.pending = make([]_PostedEvent, 0)
+	b.current = make(map[reflect.Type][]any)
+
+	handlers := make(map[reflect.Type][]_EventHandler)
+
+	for _, p := range posted {
+		b.current[p.eventType] = append(b.current[p.eventType], p.event)
+
+		if _, ok := handlers[p.eventType]; !ok {
+			handlers[p.eventType] = slices.Clone(b.handlers[p.eventType])
+		}
+	}
+
+	b.lock.Unlock()
+
+	for _, p := range posted {
+		for _, h := range handlers[p.eventType] {
+			h.call(p.event)
+		}
+	}
+}
+
+// Removes the handler from the bus, safe to call multiple times.
+func (s *EventSubscription) Unsubscribe() {
+	s.bus.lock.Lock()
+	defer s.bus.lock.Unlock()
+
+	s.bus.handlers[s.eventType] = slices.DeleteFunc(s.bus.handlers[s.eventType], func(h _EventHandler) bool {
+		return h.id == s.id
+	})
+}
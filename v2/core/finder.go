@@ -1,57 +1,117 @@
 package core
 
+import (
+	"cmp"
+	"iter"
+	"slices"
+)
+
 type FinderI interface {
 	GetOne() Entity
 	GetMany() []Entity
+	GetRows() []FinderRow
+	All() iter.Seq[Entity]
 
 	Has(components ...string) FinderI
+	Without(components ...string) FinderI
+	AnyOf(components ...string) FinderI
+	Optional(components ...string) FinderI
 	Where(predicate func(Entity) bool) FinderI
+	ChildOf(parent EntityID) FinderI
+	OrderById() FinderI
+
+	Tagged(tags ...string) FinderI
+	NotTagged(tags ...string) FinderI
+
+	Added(components ...string) FinderI
+	Changed(components ...string) FinderI
+	Removed(components ...string) FinderI
+	Since(tick uint64) FinderI
+}
+
+// Matched entity with its components, returned by Finder.GetRows.
+type FinderRow struct {
+	Entity Entity
+
+	// Components in the Has types order followed by the Optional types order, missing optional components are nil.
+	Components []Component
 }
 
-// Finder implementation, stores entity IDs matched by filters.
+// Internal, component & tag filters converted to bitsets, built once per search.
+type finderMasks struct {
+	required bitset
+	excluded bitset
+	anyOf    []bitset
+
+	tagged    bitset
+	notTagged bitset
+}
+
+// Finder implementation, stores filters and applies them when entities are requested.
+// Component filters are matched against archetype signatures, the search starts from the smallest component bucket.
 type Finder struct {
-	es        *EntityStore
-	entityIds []EntityID
+	es *EntityStore
+
+	required   []ComponentType
+	excluded   []ComponentType
+	anyOf      [][]ComponentType
+	optional   []ComponentType
+	predicates []func(Entity) bool
+	ordered    bool
+
+	// Parent filters, the search starts from children of the first parent.
+	childOf []EntityID
+
+	// Tag filters, matched against entity tag bitsets.
+	tagged    []string
+	notTagged []string
+
+	// Change detection filters, compared against since or the running system last run tick.
+	added    []ComponentType
+	changed  []ComponentType
+	removed  []ComponentType
+	since    uint64
+	hasSince bool
 
 	FinderI
 }
 
 // Default finder implementation constructor.
 func MakeFinder(es *EntityStore) FinderI {
-	ids := make([]EntityID, 0)
-
-	for id := range es.entities {
-		ids = append(ids, id)
-	}
-
 	return &Finder{
-		es:        es,
-		entityIds: ids,
+		es:         es,
+		required:   make([]ComponentType, 0),
+		excluded:   make([]ComponentType, 0),
+		anyOf:      make([][]ComponentType, 0),
+		optional:   make([]ComponentType, 0),
+		predicates: make([]func(Entity) bool, 0),
 	}
 }
 
 // Filters entities by attached to them components presence.
 func (f *Finder) Has(components ...string) FinderI {
-	matched := make([]EntityID, 0)
-
-	for _, id := range f.entityIds {
-		isMatching := true
-
-		for _, c := range components {
-			_, isCompExists := f.es.ec_map[id][c]
+	f.required = append(f.required, components...)
+	return f
+}
 
-			if !isCompExists {
-				isMatching = false
-				break
-			}
-		}
+// Filters out entities that have any of provided components.
+func (f *Finder) Without(components ...string) FinderI {
+	f.excluded = append(f.excluded, components...)
+	return f
+}
 
-		if isMatching {
-			matched = append(matched, id)
-		}
+// Filters entities that have at least one of provided components.
+func (f *Finder) AnyOf(components ...string) FinderI {
+	if len(components) != 0 {
+		f.anyOf = append(f.anyOf, components)
 	}
 
-	f.entityIds = matched
+	return f
+}
+
+// Adds optional components to GetRows results, doesn't filter entities.
+func (f *Finder) Optional(components ...string) FinderI {
+	f.optional = append(f.optional, components...)
 	return f
 }
 
@@ -61,26 +121,70 @@ func (f *Finder) Where(predicate func(Entity) bool) FinderI {
 		return f
 	}
 
-	matched := make([]EntityID, 0)
+	f.predicates = append(f.predicates, predicate)
+	return f
+}
 
-	for _, id := range f.entityIds {
-		e := f.es.entities[id]
+// Filters entities by their parent, only children of the parent are searched.
+func (f *Finder) ChildOf(parent EntityID) FinderI {
+	f.childOf = append(f.childOf, parent)
+	return f
+}
 
-		if predicate(e) {
-			matched = append(matched, id)
-		}
-	}
+// Sorts matched entities by ascending entity ID, otherwise the order is not defined.
+func (f *Finder) OrderById() FinderI {
+	f.ordered = true
+	return f
+}
+
+// Filters entities that have all provided tags.
+func (f *Finder) Tagged(tags ...string) FinderI {
+	f.tagged = append(f.tagged, tags...)
+	return f
+}
 
-	f.entityIds = matched
+// Filters out entities that have any of provided tags.
+func (f *Finder) NotTagged(tags ...string) FinderI {
+	f.notTagged = append(f.notTagged, tags...)
+	return f
+}
+
+// Filters entities whose provided components were attached since the running system last run (see Since).
+func (f *Finder) Added(components ...string) FinderI {
+	f.required = append(f.required, components...)
+	f.added = append(f.added, components...)
+	return f
+}
+
+// Filters entities whose provided components were attached or changed since the running system last run (see Since).
+// Components are changed by replacing them, with Entity.GetMut or EntityStore.MarkChanged.
+func (f *Finder) Changed(components ...string) FinderI {
+	f.required = append(f.required, components...)
+	f.changed = append(f.changed, components...)
+	return f
+}
+
+// Filters entities whose provided components were removed since the running system last run (see Since).
+// Only alive entities are matched, use EntityStore.RemovedSince to get removed entities too.
+func (f *Finder) Removed(components ...string) FinderI {
+	f.removed = append(f.removed, components...)
+	return f
+}
+
+// Sets the tick change filters compare against, by default it's EntityStore.LastRunTick.
+func (f *Finder) Since(tick uint64) FinderI {
+	f.since = tick
+	f.hasSince = true
 	return f
 }
 
 // Returns all matched entities list.
 func (f *Finder) GetMany() []Entity {
-	entities := make([]Entity, len(f.entityIds))
+	refs := f.find(-1)
+	entities := make([]Entity, len(refs))
 
-	for i, id := range f.entityIds {
-		entities[i] = makeEntity(id, f.es)
+	for i, e := range refs {
+		entities[i] = e
 	}
 
 	return entities
@@ -88,9 +192,312 @@ func (f *Finder) GetMany() []Entity {
 
 // Returns the first matched entity.
 func (f *Finder) GetOne() Entity {
-	if len(f.entityIds) == 0 {
+	limit := 1
+
+	// the first entity is known only after sorting all of them
+	if f.ordered {
+		limit = -1
+	}
+
+	refs := f.find(limit)
+
+	if len(refs) == 0 {
 		return nil
 	}
 
-	return makeEntity(f.entityIds[0], f.es)
+	return refs[0]
+}
+
+// Returns all matched entities with their required & optional components.
+func (f *Finder) GetRows() []FinderRow {
+	refs := f.find(-1)
+	rows := make([]FinderRow, 0, len(refs))
+	types := slices.Concat(f.required, f.optional)
+
+	f.es.readLock()
+	defer f.es.readUnlock()
+
+	for _, e := range refs {
+		loc, ok := f.es.location(e.id)
+
+		// removed by another goroutine in the concurrent mode
+		if !ok {
+			continue
+		}
+
+		comps := make([]Component, len(types))
+
+		for j, t := range types {
+			comps[j] = loc.arch.get(loc.row, t)
+		}
+
+		rows = append(rows, FinderRow{
+			Entity:     e,
+			Components: comps,
+		})
+	}
+
+	return rows
+}
+
+// Internal, returns matched entities, limit < 0 means no limit.
+// Predicates are called without holding the store lock, so they may use entity methods in the concurrent mode.
+func (f *Finder) find(limit int) []*entityRef {
+	if len(f.predicates) == 0 {
+		return f.sort(f.matchComponents(limit))
+	}
+
+	matched := make([]*entityRef, 0)
+
+	for _, e := range f.matchComponents(-1) {
+		if !f.matchPredicates(e) {
+			continue
+		}
+
+		matched = append(matched, e)
+
+		if limit >= 0 && len(matched) >= limit {
+			break
+		}
+	}
+
+	return f.sort(matched)
+}
+
+// Internal, returns entities matched by component & change filters, limit < 0 means no limit.
+func (f *Finder) matchComponents(limit int) []*entityRef {
+	f.es.readLock()
+	defer f.es.readUnlock()
+
+	matched := make([]*entityRef, 0)
+
+	f.each(func(e *entityRef) bool {
+		matched = append(matched, e)
+		return limit < 0 || len(matched) < limit
+	})
+
+	return matched
+}
+
+// Internal, calls fn for entities passing component, change, tag & parent filters, stops when fn returns false.
+// Predicates are not applied. The store must be locked for reading in the concurrent mode.
+func (f *Finder) each(fn func(e *entityRef) bool) {
+	since := f.sinceTick()
+	removed := f.removedSince(since)
+	masks, ok := f.masks()
+
+	if !ok {
+		return
+	}
+
+	if len(f.childOf) > 0 {
+		f.eachChild(masks, since, removed, fn)
+		return
+	}
+
+	for _, a := range f.candidates() {
+		if !f.matchArchetype(a, masks) {
+			continue
+		}
+
+		for row := 0; row < a.len(); row++ {
+			e := a.entities[row]
+
+			if !f.matchChanges(a, row, since, removed) || !f.matchTags(e.id, masks) {
+				continue
+			}
+
+			if !fn(e) {
+				return
+			}
+		}
+	}
+}
+
+// Internal, calls fn for children of the parent filter passing other filters, stops when fn returns false.
+func (f *Finder) eachChild(masks finderMasks, since uint64, removed map[EntityID]bool, fn func(e *entityRef) bool) {
+	parent := f.childOf[0]
+
+	// an entity has a single parent
+	for _, p := range f.childOf[1:] {
+		if p != parent {
+			return
+		}
+	}
+
+	// copied, so fn may change relations
+	for _, id := range slices.Clone(f.es.children[parent]) {
+		loc, ok := f.es.location(id)
+
+		if !ok || !f.matchArchetype(loc.arch, masks) {
+			continue
+		}
+
+		if !f.matchChanges(loc.arch, loc.row, since, removed) || !f.matchTags(id, masks) {
+			continue
+		}
+
+		if !fn(loc.arch.entities[loc.row]) {
+			return
+		}
+	}
+}
+
+// Internal, sorts matched entities if OrderById is set.
+func (f *Finder) sort(matched []*entityRef) []*entityRef {
+	if f.ordered {
+		slices.SortFunc(matched, func(a, b *entityRef) int {
+			return cmp.Compare(a.id, b.id)
+		})
+	}
+
+	return matched
+}
+
+// Internal, returns archetypes to search in: the smallest bucket of required types or all archetypes.
+func (f *Finder) candidates() []*archetype {
+	if len(f.required) == 0 {
+		return f.es.archetypes
+	}
+
+	var smallest []*archetype
+	smallestCount := -1
+
+	for _, t := range f.required {
+		bucket := f.es.componentIndex[t]
+		count := 0
+
+		for _, a := range bucket {
+			count += a.len()
+		}
+
+		if smallestCount == -1 || count < smallestCount {
+			smallest = bucket
+			smallestCount = count
+		}
+	}
+
+	return smallest
+}
+
+// Internal, returns true if the archetype signature passes component filters.
+func (f *Finder) matchArchetype(a *archetype, masks finderMasks) bool {
+	if !a.signature.containsAll(masks.required) || a.signature.intersects(masks.excluded) {
+		return false
+	}
+
+	for _, group := range masks.anyOf {
+		if !a.signature.intersects(group) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Internal, returns the change detection threshold tick.
+func (f *Finder) sinceTick() uint64 {
+	if f.hasSince {
+		return f.since
+	}
+
+	return f.es.systemTick
+}
+
+// Internal, returns IDs with all Removed types removed after the since tick, nil if there is no such filter.
+func (f *Finder) removedSince(since uint64) map[EntityID]bool {
+	if len(f.removed) == 0 {
+		return nil
+	}
+
+	types := slices.Compact(slices.Sorted(slices.Values(f.removed)))
+	counts := make(map[EntityID]int)
+
+	for _, t := range types {
+		for _, id := range f.es.removedSince(t, since) {
+			counts[id]++
+		}
+	}
+
+	ids := make(map[EntityID]bool, len(counts))
+
+	for id, n := range counts {
+		if n == len(types) {
+			ids[id] = true
+		}
+	}
+
+	return ids
+}
+
+// Internal, returns true if the archetype row passes change detection filters.
+func (f *Finder) matchChanges(a *archetype, row int, since uint64, removed map[EntityID]bool) bool {
+	for _, t := range f.added {
+		if !a.addedSince(row, t, since) {
+			return false
+		}
+	}
+
+	for _, t := range f.changed {
+		if !a.changedSince(row, t, since) {
+			return false
+		}
+	}
+
+	if removed != nil && !removed[a.entities[row].id] {
+		return false
+	}
+
+	return true
+}
+
+// Internal, converts filters to bitsets, false if nothing can match (a Has or Tagged type was never used).
+// Never used Without, AnyOf & NotTagged types can't be attached, so they are skipped.
+func (f *Finder) masks() (finderMasks, bool) {
+	required, ok := f.es.componentMask(f.required)
+
+	if !ok {
+		return finderMasks{}, false
+	}
+
+	tagged, ok := f.es.tagMask(f.tagged)
+
+	if !ok {
+		return finderMasks{}, false
+	}
+
+	masks := finderMasks{
+		required:  required,
+		excluded:  f.es.knownComponentMask(f.excluded),
+		anyOf:     make([]bitset, len(f.anyOf)),
+		tagged:    tagged,
+		notTagged: f.es.knownTagMask(f.notTagged),
+	}
+
+	for i, group := range f.anyOf {
+		masks.anyOf[i] = f.es.knownComponentMask(group)
+	}
+
+	return masks, true
+}
+
+// Internal, returns true if the entity passes tag filters.
+func (f *Finder) matchTags(id EntityID, masks finderMasks) bool {
+	if masks.tagged == nil && masks.notTagged == nil {
+		return true
+	}
+
+	tags := f.es.slots[id.Index()].tags
+	return tags.containsAll(masks.tagged) && !tags.intersects(masks.notTagged)
+}
+
+// Internal, returns true if the entity passes all predicates.
+func (f *Finder) matchPredicates(e Entity) bool {
+	for _, p := range f.predicates {
+		if !p(e) {
+			return false
+		}
+	}
+
+	return true
 }
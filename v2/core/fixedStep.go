@@ -0,0 +1,130 @@
+package core
+
+import (
+	"errors"
+	"time"
+)
+
+// System that renders interpolated state between fixed steps, see ECS.SetFixedStep.
+type SystemWithInterpolation interface {
+	System
+
+	// Called once per ECS.Process after fixed steps, alpha is in [0, 1) range.
+	// Blend the previous & the current simulation states using alpha.
+	Interpolate(entityStore *EntityStore, alpha float64)
+}
+
+// Internal fixed step state.
+type _FixedStep struct {
+	step     time.Duration
+	maxSteps int
+
+	// Not simulated yet real time.
+	accumulator time.Duration
+	lastProcess time.Time
+	alpha       float64
+
+	// Simulated time & simulated time of the last system call.
+	simulated    time.Duration
+	lastCallTime map[string]time.Duration
+}
+
+// Enables the fixed step mode: Process accumulates elapsed real time and runs fixed steps of the provided length,
+// at most maxSteps per Process call (excess time is dropped), systems receive constant dt.
+// System frequencies are measured in simulated time. Zero step disables the mode.
+func (e *ECS) SetFixedStep(step time.Duration, maxSteps int) {
+	e.fixedStep = _FixedStep{
+		step:         step,
+		maxSteps:     max(maxSteps, 1),
+		lastCallTime: make(map[string]time.Duration),
+	}
+}
+
+// Returns the fixed step length, zero if the fixed step mode is disabled.
+func (e *ECS) FixedStep() time.Duration {
+	return e.fixedStep.step
+}
+
+// Returns the interpolation alpha, part of the step accumulated but not simulated yet, in [0, 1) range.
+func (e *ECS) Alpha() float64 {
+	return e.fixedStep.alpha
+}
+
+// Runs exactly one fixed step, independent of real time. Use it for deterministic stepping & tests.
+// Each step is a frame for the event bus.
+// Returns joined errors of failed deferred commands (see CommandBuffer.Apply).
+// Panics if the fixed step mode is disabled.
+func (e *ECS) Step() error {
+	fs := &e.fixedStep
+
+	if fs.step <= 0 {
+		panic("Fixed step mode is disabled, call SetFixedStep first")
+	}
+
+	fs.simulated += fs.step
+	errs := make([]error, 0)
+	errs = append(errs, e.applyStateTransition())
+	e.Events.Flush()
+
+	due := make([]System, 0)
+	elapsed := make(map[string]time.Duration)
+
+	for _, p := range e.SystemStore.Priority() {
+		s := e.SystemStore.systems[p.system]
+		lastCall, ok := fs.lastCallTime[p.system]
+
+		// a new system is considered called one step ago
+		if !ok {
+			lastCall = fs.simulated - fs.step
+			fs.lastCallTime[p.system] = lastCall
+		}
+
+		sElapsed := fs.simulated - lastCall
+
+		if sElapsed >= (time.Duration(s.Frequency()) * time.Millisecond) {
+			due = append(due, s)
+			elapsed[p.system] = sElapsed
+		}
+	}
+
+	errs = append(errs, e.processSystems(due, elapsed))
+
+	for _, s := range due {
+		fs.lastCallTime[s.Type()] = fs.simulated
+	}
+
+	errs = append(errs, e.EntityStore.Commands().Apply())
+	return errors.Join(errs...)
+}
+
+// Internal, runs fixed steps for the elapsed real time & interpolation systems, returns joined command errors.
+func (e *ECS) processFixed() error {
+	fs := &e.fixedStep
+	now := e.SystemStore.clock.Now()
+
+	if fs.lastProcess.IsZero() {
+		fs.lastProcess = now
+	}
+
+	fs.accumulator += now.Sub(fs.lastProcess)
+	fs.lastProcess = now
+	errs := make([]error, 0)
+
+	for steps := 0; fs.accumulator >= fs.step && steps < fs.maxSteps; steps++ {
+		errs = append(errs, e.Step())
+		fs.accumulator -= fs.step
+	}
+
+	// dropping time that can't be caught up
+	fs.accumulator %= fs.step
+	fs.alpha = float64(fs.accumulator) / float64(fs.step)
+
+	for _, p := range e.SystemStore.Priority() {
+		if s, ok := e.SystemStore.systems[p.system].(SystemWithInterpolation); ok && e.shouldRun(s) {
+			s.Interpolate(&e.EntityStore, fs.alpha)
+		}
+	}
+
+	errs = append(errs, e.EntityStore.Commands().Apply())
+	return errors.Join(errs...)
+}
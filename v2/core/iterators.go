@@ -0,0 +1,142 @@
+package core
+
+import "iter"
+
+// Pair of typed components yielded by Query2 iterators.
+type Pair[A, B Component] struct {
+	A A
+	B B
+}
+
+// Typed query over a single component type, see MakeQuery1.
+type Query1[A Component] struct {
+	query *Query
+}
+
+// Typed query over two component types, see MakeQuery2.
+type Query2[A, B Component] struct {
+	query *Query
+}
+
+// Returns an iterator over all stored entities, supports early break & allocates nothing per entity.
+// Don't add or remove entities & components while iterating, use a CommandBuffer instead.
+// In the concurrent mode a snapshot of entities is iterated.
+func (es *EntityStore) Each() iter.Seq[Entity] {
+	return func(yield func(Entity) bool) {
+		if es.lock != nil {
+			for _, e := range es.GetAll() {
+				if !yield(e) {
+					return
+				}
+			}
+
+			return
+		}
+
+		for _, a := range es.archetypes {
+			for row := 0; row < a.len(); row++ {
+				if !yield(a.entities[row]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Returns an iterator over matched entities, filters are applied lazily, so breaking early skips the rest search.
+// With OrderById or in the concurrent mode matched entities are collected first.
+// Don't add or remove entities & components while iterating, use a CommandBuffer instead.
+func (f *Finder) All() iter.Seq[Entity] {
+	return func(yield func(Entity) bool) {
+		if f.ordered || f.es.lock != nil {
+			for _, e := range f.find(-1) {
+				if !yield(e) {
+					return
+				}
+			}
+
+			return
+		}
+
+		f.each(func(e *entityRef) bool {
+			return !f.matchPredicates(e) || yield(e)
+		})
+	}
+}
+
+// Returns an iterator over matched entities & their components in the query types order.
+// The components slice is reused between iterations, copy it if you need to keep it.
+// Don't add or remove entities & components while iterating, use a CommandBuffer instead.
+func (q *Query) All() iter.Seq2[Entity, []Component] {
+	return func(yield func(Entity, []Component) bool) {
+		n := len(q.types)
+
+		if q.es.lock != nil {
+			entities, components := q.snapshot()
+
+			for i, e := range entities {
+				if !yield(e, components[i*n:(i+1)*n:(i+1)*n]) {
+					return
+				}
+			}
+
+			return
+		}
+
+		buffer := make([]Component, n)
+
+		for _, m := range q.matches {
+			for row := 0; row < m.arch.len(); row++ {
+				for i, col := range m.columns {
+					buffer[i] = m.arch.columns[col][row]
+				}
+
+				if !yield(m.arch.entities[row], buffer) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Returns a typed query matching entities with component A, backed by the cached EntityStore.Query.
+func MakeQuery1[A Component](es *EntityStore) *Query1[A] {
+	return &Query1[A]{query: es.Query(TypeOf[A]())}
+}
+
+// Returns the underlying untyped query.
+func (q *Query1[A]) Query() *Query {
+	return q.query
+}
+
+// Returns an iterator over matched entities & their typed components.
+func (q *Query1[A]) All() iter.Seq2[Entity, A] {
+	return func(yield func(Entity, A) bool) {
+		for e, comps := range q.query.All() {
+			if !yield(e, comps[0].(A)) {
+				return
+			}
+		}
+	}
+}
+
+// Returns a typed query matching entities with components A & B, backed by the cached EntityStore.Query.
+func MakeQuery2[A, B Component](es *EntityStore) *Query2[A, B] {
+	return &Query2[A, B]{query: es.Query(TypeOf[A](), TypeOf[B]())}
+}
+
+// Returns the underlying untyped query.
+func (q *Query2[A, B]) Query() *Query {
+	return q.query
+}
+
+// Returns an iterator over matched entities & pairs of their typed components.
+func (q *Query2[A, B]) All() iter.Seq2[Entity, Pair[A, B]] {
+	return func(yield func(Entity, Pair[A, B]) bool) {
+		for e, comps := range q.query.All() {
+			if !yield(e, Pair[A, B]{A: comps[0].(A), B: comps[1].(B)}) {
+				return
+			}
+		}
+	}
+}
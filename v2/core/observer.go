@@ -12,10 +12,58 @@ type Observer interface {
 	OnDetach(componentType string, e Entity)
 }
 
+// Observer notified once per component type by batch operations (NewBatch, AddToMany, RemoveAll).
+// Observers without batch methods are notified about each entity separately.
+type BatchObserver interface {
+	Observer
+
+	// Notifies about component attachment to multiple entities.
+	OnAttachBatch(componentType string, entities []Entity)
+	// Notifies about component detachment from multiple entities.
+	OnDetachBatch(componentType string, entities []Entity)
+}
+
+// Observer of tags, notified with OnAttach & OnDetach called with the tag name.
+// Tags are observed separately, so a tag named like a component type doesn't notify component observers.
+type TagObserver interface {
+	Observer
+
+	// Returns observed tags.
+	GetObservedTags() []string
+}
+
+// Internal, an added observer with its observed component types & tags converted to bitsets,
+// so they are matched without string comparisons.
+type observerEntry struct {
+	id         uint64
+	observer   Observer
+	components bitset
+	tags       bitset
+}
+
+// Internal, returns an observer entry with bitsets of currently observed types & tags.
+// The store must be locked for writing.
+func (es *EntityStore) makeObserverEntry(id uint64, observer Observer) observerEntry {
+	entry := observerEntry{id: id, observer: observer}
+
+	for _, t := range observer.GetObservedTypes() {
+		entry.components.set(es.componentId(t))
+	}
+
+	if o, ok := observer.(TagObserver); ok {
+		for _, tag := range o.GetObservedTags() {
+			entry.tags.set(es.tagBit(tag))
+		}
+	}
+
+	return entry
+}
+
 // Base observer implementation for notifying systems.
 type BaseObserver struct {
 	systemStore     *SystemStore
 	observedTypes   []string
+	observedTags    []string
 	systemsToNotify []string
 }
 
@@ -34,11 +82,21 @@ func (o *BaseObserver) GetObservedTypes() []string {
 	return o.observedTypes
 }
 
-// Sets observed component types.
+// Sets observed component types, call ObserverHandle.Refresh to apply them to an added observer.
 func (o *BaseObserver) SetObservedTypes(types ...string) {
 	o.observedTypes = types
 }
 
+// Returns observed tags.
+func (o *BaseObserver) GetObservedTags() []string {
+	return o.observedTags
+}
+
+// Sets observed tags, call ObserverHandle.Refresh to apply them to an added observer.
+func (o *BaseObserver) SetObservedTags(tags ...string) {
+	o.observedTags = tags
+}
+
 // Notifies systems with observable hooks about component attachment.
 func (o *BaseObserver) OnAttach(componentType string, e Entity) {
 	for _, s := range o.systemsToNotify {
@@ -74,6 +132,7 @@ func NewObserver(systemStore *SystemStore) *BaseObserver {
 	return &BaseObserver{
 		systemStore:     systemStore,
 		observedTypes:   []string{},
+		observedTags:    []string{},
 		systemsToNotify: []string{},
 	}
 }
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// Returned when spawning a prefab that was not registered.
+var ErrUnknownPrefab = errors.New("unknown prefab")
+
+// Returned when prefab children reference their ancestor prefab.
+var ErrPrefabCycle = errors.New("prefab cycle")
+
+// Named entity template. Components are deep-cloned on each spawn (see CloneComponent), children are names of other prefabs
+// spawned as child entities (see SetParent).
+type Prefab struct {
+	Name       string
+	Components []Component
+	Children   []string
+}
+
+// Internal JSON representation of a prefab, components are keyed by type.
+type _JSONPrefab struct {
+	Components map[string]json.RawMessage `json:"components"`
+	Children   []string                   `json:"children,omitempty"`
+}
+
+// Registers a prefab, registering the same name again replaces the prefab.
+// Prefab components are owned by the store, don't mutate them after registering.
+func (es *EntityStore) RegisterPrefab(prefab Prefab) {
+	es.writeLock()
+	defer es.writeUnlock()
+
+	es.prefabs[prefab.Name] = &prefab
+}
+
+// Returns a registered prefab, ok is false if no such prefab exists.
+func (es *EntityStore) GetPrefab(name string) (Prefab, bool) {
+	es.readLock()
+	defer es.readUnlock()
+
+	p, ok := es.prefabs[name]
+
+	if !ok {
+		return Prefab{}, false
+	}
+
+	return *p, true
+}
+
+// Creates an entity from the prefab & its child prefabs. Overrides replace prefab components of the same type
+// or are attached in addition to them, they are not cloned. Returns ErrUnknownPrefab or ErrPrefabCycle
+// if the prefab or any of its children can't be spawned, no entities are created in that case.
+func (es *EntityStore) SpawnPrefab(name string, overrides ...Component) (Entity, error) {
+	es.readLock()
+	err := es.checkPrefab(name, nil)
+	es.readUnlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return es.spawnPrefab(name, overrides), nil
+}
+
+// Registers prefabs from JSON, components are created with the component registry (see RegisterComponent).
+// The format is an object of prefabs by name:
+//
+//	{"player": {"components": {"position": {"X": 1}}, "children": ["weapon"]}}
+func (es *EntityStore) LoadPrefabsJSON(r io.Reader) error {
+	data := make(map[string]_JSONPrefab)
+
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return fmt.Errorf("decoding prefabs: %w", err)
+	}
+
+	prefabs := make([]Prefab, 0, len(data))
+
+	// sorted for deterministic components order
+	for _, name := range slices.Sorted(maps.Keys(data)) {
+		jp := data[name]
+		prefab := Prefab{Name: name, Children: jp.Children}
+
+		for _, cType := range slices.Sorted(maps.Keys(jp.Components)) {
+			c, err := NewComponent(cType)
+
+			if err != nil {
+				return fmt.Errorf("prefab %s: %w", name, err)
+			}
+
+			if err := json.Unmarshal(jp.Components[cType], c); err != nil {
+				return fmt.Errorf("prefab %s: component %s: %w", name, cType, err)
+			}
+
+			prefab.Components = append(prefab.Components, c)
+		}
+
+		prefabs = append(prefabs, prefab)
+	}
+
+	for _, prefab := range prefabs {
+		es.RegisterPrefab(prefab)
+	}
+
+	return nil
+}
+
+// Internal, checks the prefab & its children exist & don't form a cycle, path is the ancestor prefabs chain.
+func (es *EntityStore) checkPrefab(name string, path []string) error {
+	if slices.Contains(path, name) {
+		return fmt.Errorf("%w: %s", ErrPrefabCycle, name)
+	}
+
+	p, ok := es.prefabs[name]
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownPrefab, name)
+	}
+
+	path = append(path, name)
+
+	for _, child := range p.Children {
+		if err := es.checkPrefab(child, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Internal, spawns a checked prefab with its children.
+func (es *EntityStore) spawnPrefab(name string, overrides []Component) Entity {
+	p, _ := es.GetPrefab(name)
+	components := make([]Component, 0, len(p.Components)+len(overrides))
+
+	for _, c := range p.Components {
+		overridden := slices.ContainsFunc(overrides, func(o Component) bool {
+			return o.Type() == c.Type()
+		})
+
+		if !overridden {
+			components = append(components, CloneComponent(c))
+		}
+	}
+
+	e := es.New(append(components, overrides...)...)
+
+	for _, child := range p.Children {
+		es.SetParent(es.spawnPrefab(child, nil).Id(), e.Id())
+	}
+
+	return e
+}
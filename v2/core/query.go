@@ -0,0 +1,187 @@
+package core
+
+import "sync/atomic"
+
+// Internal, an archetype matched by a query with column indices in query types order.
+type queryMatch struct {
+	arch    *archetype
+	columns []int
+}
+
+// Cached query, stores archetypes matched by component types.
+// The matching set is kept up to date by the EntityStore, so iterating it does not scan all entities.
+type Query struct {
+	es    *EntityStore
+	types []ComponentType
+
+	matches []queryMatch
+
+	// Reusable buffer passed to Each callback, taken atomically since queries may be iterated in parallel.
+	buffer atomic.Pointer[[]Component]
+}
+
+// Returns a registered query matching entities that have all provided component types.
+// The same query instance is returned for the same types order.
+func (es *EntityStore) Query(componentTypes ...string) *Query {
+	key := makeArchetypeKey(componentTypes)
+
+	es.queryLock.RLock()
+	q, ok := es.queries[key]
+	es.queryLock.RUnlock()
+
+	if ok {
+		return q
+	}
+
+	// archetypes are read while matching, the store lock goes first
+	es.readLock()
+	defer es.readUnlock()
+
+	es.queryLock.Lock()
+	defer es.queryLock.Unlock()
+
+	if q, ok := es.queries[key]; ok {
+		return q
+	}
+
+	q = &Query{
+		es:      es,
+		types:   componentTypes,
+		matches: make([]queryMatch, 0),
+	}
+
+	for _, a := range es.archetypes {
+		q.tryMatch(a)
+	}
+
+	es.queries[key] = q
+	return q
+}
+
+// Returns query component types.
+func (q *Query) Types() []ComponentType {
+	return q.types
+}
+
+// Returns matched entities count.
+func (q *Query) Count() int {
+	q.es.readLock()
+	defer q.es.readUnlock()
+
+	count := 0
+
+	for _, m := range q.matches {
+		count += m.arch.len()
+	}
+
+	return count
+}
+
+// Calls fn for every matched entity, components are passed in the query types order.
+// The components slice is reused between calls, copy it if you need to keep it.
+// Don't add or remove entities & components inside fn, use a CommandBuffer instead.
+// In the concurrent mode matched rows are copied under the store lock & fn is called unlocked.
+func (q *Query) Each(fn func(e Entity, components []Component)) {
+	if q.es.lock != nil {
+		q.eachConcurrent(fn)
+		return
+	}
+
+	buffer := q.buffer.Swap(nil)
+
+	if buffer == nil {
+		comps := make([]Component, len(q.types))
+		buffer = &comps
+	}
+
+	defer q.buffer.CompareAndSwap(nil, buffer)
+
+	for _, m := range q.matches {
+		for row := 0; row < m.arch.len(); row++ {
+			for i, col := range m.columns {
+				(*buffer)[i] = m.arch.columns[col][row]
+			}
+
+			fn(m.arch.entities[row], *buffer)
+		}
+	}
+}
+
+// Returns the first matched entity, may return nil.
+func (q *Query) GetOne() Entity {
+	q.es.readLock()
+	defer q.es.readUnlock()
+
+	for _, m := range q.matches {
+		if m.arch.len() != 0 {
+			return m.arch.entities[0]
+		}
+	}
+
+	return nil
+}
+
+// Returns a list of all matched entities.
+func (q *Query) GetMany() []Entity {
+	q.es.readLock()
+	defer q.es.readUnlock()
+
+	entities := make([]Entity, 0)
+
+	for _, m := range q.matches {
+		for _, e := range m.arch.entities {
+			entities = append(entities, e)
+		}
+	}
+
+	return entities
+}
+
+// Internal, Each version for the concurrent mode, iterates a snapshot of matched rows.
+func (q *Query) eachConcurrent(fn func(e Entity, components []Component)) {
+	entities, components := q.snapshot()
+	n := len(q.types)
+
+	for i, e := range entities {
+		fn(e, components[i*n:(i+1)*n:(i+1)*n])
+	}
+}
+
+// Internal, copies matched entities & their components (in query types order) under the store lock.
+func (q *Query) snapshot() ([]Entity, []Component) {
+	q.es.readLock()
+	defer q.es.readUnlock()
+
+	entities := make([]Entity, 0)
+	components := make([]Component, 0)
+
+	for _, m := range q.matches {
+		for row := 0; row < m.arch.len(); row++ {
+			for _, col := range m.columns {
+				components = append(components, m.arch.columns[col][row])
+			}
+
+			entities = append(entities, m.arch.entities[row])
+		}
+	}
+
+	return entities, components
+}
+
+// Internal, adds the archetype to the matching set if it has all query types.
+func (q *Query) tryMatch(a *archetype) {
+	if !q.es.signatureHas(a, q.types) {
+		return
+	}
+
+	columns := make([]int, len(q.types))
+
+	for i, t := range q.types {
+		columns[i] = a.index[t]
+	}
+
+	q.matches = append(q.matches, queryMatch{
+		arch:    a,
+		columns: columns,
+	})
+}
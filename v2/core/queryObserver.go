@@ -0,0 +1,211 @@
+package core
+
+import "slices"
+
+// Internal query observer event kinds.
+type _ObserverEvent int
+
+const (
+	_EventEnter _ObserverEvent = iota
+	_EventLeave
+	_EventSet
+	_EventDestroy
+)
+
+// Internal, a query observer callback with the observed types signature.
+type subscription struct {
+	id    uint64
+	event _ObserverEvent
+	mask  bitset
+	fn    func(e Entity, c Component)
+}
+
+// Internal, an entity change made by addTo, observers are notified about it after the store is unlocked.
+type entityChange struct {
+	e       *entityRef
+	created bool
+
+	// Entity archetype signatures before & after the change.
+	before bitset
+	after  bitset
+
+	// Attached components, replaced is true for components that replaced attached ones.
+	components []Component
+	replaced   []bool
+}
+
+// Observes entities that have all observed component types (all entities if there are no types), created by
+// EntityStore.Observe. Callbacks are called without holding the store lock, use a CommandBuffer to change the store.
+type QueryObserver struct {
+	es    *EntityStore
+	types []ComponentType
+}
+
+// Handle of an added observer or a query observer callback, used to unsubscribe.
+type ObserverHandle struct {
+	es *EntityStore
+	id uint64
+}
+
+// Returns an observer of entities that have all provided component types.
+func (es *EntityStore) Observe(componentTypes ...string) *QueryObserver {
+	return &QueryObserver{
+		es:    es,
+		types: slices.Clone(componentTypes),
+	}
+}
+
+// Calls fn when an entity starts matching: it's created with observed types or gets the last missing one.
+func (o *QueryObserver) OnEnter(fn func(e Entity)) ObserverHandle {
+	return o.subscribe(_EventEnter, func(e Entity, c Component) { fn(e) })
+}
+
+// Calls fn when an entity stops matching: an observed type is detached or the entity is removed.
+// Called before the component is detached, so it's still available.
+func (o *QueryObserver) OnLeave(fn func(e Entity)) ObserverHandle {
+	return o.subscribe(_EventLeave, func(e Entity, c Component) { fn(e) })
+}
+
+// Calls fn with the new component when an observed component of a matched entity is replaced (attached again).
+// Without observed types fn is called for any replaced component.
+func (o *QueryObserver) OnSet(fn func(e Entity, c Component)) ObserverHandle {
+	return o.subscribe(_EventSet, fn)
+}
+
+// Calls fn when a matched entity is removed, before its children & components are removed.
+func (o *QueryObserver) OnDestroy(fn func(e Entity)) ObserverHandle {
+	return o.subscribe(_EventDestroy, func(e Entity, c Component) { fn(e) })
+}
+
+// Removes the observer or the callback, does nothing if it's already removed.
+func (h ObserverHandle) Unsubscribe() {
+	if h.es == nil {
+		return
+	}
+
+	h.es.writeLock()
+	defer h.es.writeUnlock()
+
+	// copied on write, so hooks can iterate the previous lists unlocked
+	if i := slices.IndexFunc(h.es.observers, func(o observerEntry) bool { return o.id == h.id }); i != -1 {
+		h.es.observers = slices.Delete(slices.Clone(h.es.observers), i, i+1)
+	}
+
+	if i := slices.IndexFunc(h.es.subscriptions, func(s subscription) bool { return s.id == h.id }); i != -1 {
+		h.es.subscriptions = slices.Delete(slices.Clone(h.es.subscriptions), i, i+1)
+	}
+}
+
+// Rebuilds bitsets of an added observer from its current observed types & tags, call it after changing them.
+// Does nothing for query observer callbacks & removed observers.
+func (h ObserverHandle) Refresh() {
+	if h.es == nil {
+		return
+	}
+
+	h.es.writeLock()
+	defer h.es.writeUnlock()
+
+	if i := slices.IndexFunc(h.es.observers, func(o observerEntry) bool { return o.id == h.id }); i != -1 {
+		// copied on write, so hooks can iterate the previous list unlocked
+		observers := slices.Clone(h.es.observers)
+		observers[i] = h.es.makeObserverEntry(h.id, observers[i].observer)
+		h.es.observers = observers
+	}
+}
+
+// Internal, registers a callback for the event.
+func (o *QueryObserver) subscribe(event _ObserverEvent, fn func(e Entity, c Component)) ObserverHandle {
+	es := o.es
+
+	es.writeLock()
+	defer es.writeUnlock()
+
+	var mask bitset
+
+	for _, t := range o.types {
+		mask.set(es.componentId(t))
+	}
+
+	es.observerId++
+
+	es.subscriptions = append(slices.Clip(es.subscriptions), subscription{
+		id:    es.observerId,
+		event: event,
+		mask:  mask,
+		fn:    fn,
+	})
+
+	return ObserverHandle{es: es, id: es.observerId}
+}
+
+// Internal, calls enter & set callbacks matching the change, ids are type IDs of changed components.
+func notifyChanged(subscriptions []subscription, change entityChange, ids []int) {
+	for _, s := range subscriptions {
+		if !change.after.containsAll(s.mask) {
+			continue
+		}
+
+		matched := !change.created && change.before.containsAll(s.mask)
+
+		switch s.event {
+		case _EventEnter:
+			if !matched {
+				s.fn(change.e, nil)
+			}
+		case _EventSet:
+			// entered entities are reported with enter callbacks only
+			if !matched {
+				continue
+			}
+
+			for i, c := range change.components {
+				if change.replaced[i] && (len(s.mask) == 0 || s.mask.has(ids[i])) {
+					s.fn(change.e, c)
+				}
+			}
+		}
+	}
+}
+
+// Internal, calls leave callbacks of entities that stop matching after detaching the type with provided ID.
+func notifyLeft(subscriptions []subscription, e Entity, signature bitset, componentId int) {
+	for _, s := range subscriptions {
+		if s.event == _EventLeave && s.mask.has(componentId) && signature.containsAll(s.mask) {
+			s.fn(e, nil)
+		}
+	}
+}
+
+// Internal, calls callbacks of the event matching the entity being removed, leave callbacks of observed types are
+// called by notifyDetached.
+func (es *EntityStore) notifyRemoved(id EntityID, event _ObserverEvent) {
+	es.readLock()
+	loc, ok := es.location(id)
+	subscriptions := es.subscriptions
+
+	var e Entity
+
+	if ok {
+		e = loc.arch.entities[loc.row]
+	}
+
+	es.readUnlock()
+
+	if !ok {
+		return
+	}
+
+	for _, s := range subscriptions {
+		if s.event != event || !loc.arch.signature.containsAll(s.mask) {
+			continue
+		}
+
+		// observed types were reported while detaching, only observers of all entities are left
+		if event == _EventLeave && len(s.mask) != 0 {
+			continue
+		}
+
+		s.fn(e, nil)
+	}
+}
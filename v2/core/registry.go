@@ -0,0 +1,56 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Returned when a component type was not registered with RegisterComponent.
+var ErrUnknownComponent = errors.New("unknown component type")
+
+// Internal, registered component factories by component type, needed to create components from saved data.
+var componentRegistry = struct {
+	factories map[ComponentType]func() Component
+	lock      sync.RWMutex
+}{
+	factories: make(map[ComponentType]func() Component),
+}
+
+// Registers a component factory, the factory should return a new zero component.
+// Registered types can be restored from JSON & binary data. Registering the same type again replaces the factory.
+func RegisterComponent(factory func() Component) {
+	cType := factory().Type()
+
+	componentRegistry.lock.Lock()
+	defer componentRegistry.lock.Unlock()
+
+	componentRegistry.factories[cType] = factory
+}
+
+// Registers component type T, T should be a pointer to a component struct, e.g. RegisterComponentType[*PositionComponent]().
+func RegisterComponentType[T Component]() {
+	rt := reflect.TypeFor[T]()
+
+	if rt.Kind() != reflect.Pointer {
+		panic("Component type " + rt.String() + " should be a pointer")
+	}
+
+	RegisterComponent(func() Component {
+		return reflect.New(rt.Elem()).Interface().(Component)
+	})
+}
+
+// Creates a new zero component of registered type. Returns ErrUnknownComponent if the type is not registered.
+func NewComponent(componentType string) (Component, error) {
+	componentRegistry.lock.RLock()
+	factory, ok := componentRegistry.factories[componentType]
+	componentRegistry.lock.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownComponent, componentType)
+	}
+
+	return factory(), nil
+}
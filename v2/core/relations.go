@@ -0,0 +1,128 @@
+package core
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/kostayne/ecs/v2/utils"
+)
+
+// Returned when a parent assignment would make an entity its own ancestor.
+var ErrRelationCycle = errors.New("entity can't be a descendant of itself")
+
+// Sets the parent of the child entity, replaces the previous parent.
+// Children are removed together with their parent.
+func (es *EntityStore) SetParent(child EntityID, parent EntityID) error {
+	es.writeLock()
+	defer es.writeUnlock()
+
+	if _, ok := es.location(child); !ok {
+		return notAliveError(child)
+	}
+
+	if _, ok := es.location(parent); !ok {
+		return notAliveError(parent)
+	}
+
+	if child == parent || slices.Contains(es.ancestors(parent), child) {
+		return ErrRelationCycle
+	}
+
+	es.detachFromParent(child)
+
+	es.parents[child] = parent
+	es.children[parent] = append(es.children[parent], child)
+
+	return nil
+}
+
+// Detaches the child entity from its parent, the child stays alive.
+func (es *EntityStore) RemoveParent(child EntityID) error {
+	es.writeLock()
+	defer es.writeUnlock()
+
+	if _, ok := es.location(child); !ok {
+		return notAliveError(child)
+	}
+
+	es.detachFromParent(child)
+	return nil
+}
+
+// Returns the parent entity ID, ok is false if the entity has no parent.
+func (es *EntityStore) Parent(id EntityID) (EntityID, bool) {
+	es.readLock()
+	defer es.readUnlock()
+
+	parent, ok := es.parents[id]
+	return parent, ok
+}
+
+// Returns direct children IDs of the entity in the attachment order.
+func (es *EntityStore) Children(id EntityID) []EntityID {
+	es.readLock()
+	defer es.readUnlock()
+
+	return slices.Clone(es.children[id])
+}
+
+// Returns ancestors IDs of the entity, from the parent to the root.
+func (es *EntityStore) Ancestors(id EntityID) []EntityID {
+	es.readLock()
+	defer es.readUnlock()
+
+	return es.ancestors(id)
+}
+
+// Internal, returns ancestors IDs of the entity without locking.
+func (es *EntityStore) ancestors(id EntityID) []EntityID {
+	ancestors := make([]EntityID, 0)
+
+	for parent, ok := es.parents[id]; ok; parent, ok = es.parents[parent] {
+		ancestors = append(ancestors, parent)
+	}
+
+	return ancestors
+}
+
+// Walks the entity & its descendants depth-first, the entity itself has depth 0.
+// Return false from fn to skip the children of the visited entity.
+// fn is called without holding the store lock in the concurrent mode.
+func (es *EntityStore) Walk(id EntityID, fn func(e Entity, depth int) bool) {
+	e := es.lookup(id)
+
+	if e == nil {
+		return
+	}
+
+	es.walk(e, 0, fn)
+}
+
+// Internal, recursive part of Walk.
+func (es *EntityStore) walk(e Entity, depth int, fn func(e Entity, depth int) bool) {
+	if !fn(e, depth) {
+		return
+	}
+
+	for _, child := range es.Children(e.Id()) {
+		if c := es.lookup(child); c != nil {
+			es.walk(c, depth+1, fn)
+		}
+	}
+}
+
+// Internal, removes the entity from its parent children list.
+func (es *EntityStore) detachFromParent(child EntityID) {
+	parent, ok := es.parents[child]
+
+	if !ok {
+		return
+	}
+
+	delete(es.parents, child)
+	es.children[parent] = utils.ShiftRemoveI(es.children[parent], slices.Index(es.children[parent], child))
+
+	if len(es.children[parent]) == 0 {
+		delete(es.children, parent)
+	}
+}
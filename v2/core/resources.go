@@ -0,0 +1,72 @@
+package core
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Typed registry of singleton world data (input snapshot, config, RNG, assets...), values are keyed by their Go type.
+// Safe to use from systems running in parallel.
+type Resources struct {
+	values map[reflect.Type]any
+	lock   sync.RWMutex
+}
+
+// Resources constructor.
+func MakeResources() *Resources {
+	return &Resources{
+		values: make(map[reflect.Type]any),
+	}
+}
+
+// Inserts the resource of type T, replaces the previous one.
+func InsertResource[T any](r *Resources, value T) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.values[reflect.TypeFor[T]()] = value
+}
+
+// Returns the resource of type T, ok is false if no such resource exists.
+func GetResource[T any](r *Resources) (T, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	value, ok := r.values[reflect.TypeFor[T]()]
+
+	if !ok {
+		var zero T
+		return zero, false
+	}
+
+	return value.(T), true
+}
+
+// Returns the resource of type T, panics if no such resource exists.
+func MustGetResource[T any](r *Resources) T {
+	value, ok := GetResource[T](r)
+
+	if !ok {
+		panic("Resource " + reflect.TypeFor[T]().String() + " doesn't exist")
+	}
+
+	return value
+}
+
+// Returns true if the resource of type T exists.
+func HasResource[T any](r *Resources) bool {
+	_, ok := GetResource[T](r)
+	return ok
+}
+
+// Removes the resource of type T, returns false if no such resource existed.
+func RemoveResource[T any](r *Resources) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	t := reflect.TypeFor[T]()
+	_, ok := r.values[t]
+	delete(r.values, t)
+
+	return ok
+}
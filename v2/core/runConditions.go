@@ -0,0 +1,105 @@
+package core
+
+// Predicate evaluated by ECS before processing a system, the system is skipped if it returns false.
+type RunCondition func(ecs *ECS) bool
+
+// System that decides itself whether it should be processed, evaluated after SystemStore run conditions.
+type SystemWithRunCondition interface {
+	System
+
+	// Returns false to skip the system processing.
+	ShouldRun(ecs *ECS) bool
+}
+
+// Enables a previously disabled system, systems are enabled by default.
+func (ss *SystemStore) Enable(typeName string) {
+	delete(ss.disabled, typeName)
+}
+
+// Disables a system, so it's skipped by ECS.Process until enabled, its last call time is kept up to date.
+// Setup & Cleanup are still called for disabled systems.
+func (ss *SystemStore) Disable(typeName string) {
+	if _, ok := ss.systems[typeName]; ok {
+		ss.disabled[typeName] = true
+	}
+}
+
+// Returns true if the system is added & not disabled, run conditions are not evaluated.
+func (ss *SystemStore) IsEnabled(typeName string) bool {
+	_, ok := ss.systems[typeName]
+	return ok && !ss.disabled[typeName]
+}
+
+// Adds a run condition to the system, the system is processed only if all its conditions return true.
+// Does nothing if the system is not added.
+func (ss *SystemStore) AddRunCondition(typeName string, condition RunCondition) {
+	if _, ok := ss.systems[typeName]; !ok || condition == nil {
+		return
+	}
+
+	ss.conditions[typeName] = append(ss.conditions[typeName], condition)
+}
+
+// Removes all run conditions of the system.
+func (ss *SystemStore) ClearRunConditions(typeName string) {
+	delete(ss.conditions, typeName)
+}
+
+// Run condition that passes while a resource of type T exists in ECS.Resources.
+func ResourceExists[T any]() RunCondition {
+	return func(ecs *ECS) bool {
+		return HasResource[T](ecs.Resources)
+	}
+}
+
+// Run condition that inverts provided condition.
+func Not(condition RunCondition) RunCondition {
+	return func(ecs *ECS) bool {
+		return !condition(ecs)
+	}
+}
+
+// Internal, returns true if the system is enabled & all its run conditions pass.
+func (e *ECS) shouldRun(s System) bool {
+	if !e.SystemStore.IsEnabled(s.Type()) {
+		return false
+	}
+
+	for _, condition := range e.SystemStore.conditions[s.Type()] {
+		if !condition(e) {
+			return false
+		}
+	}
+
+	if c, ok := s.(SystemWithRunCondition); ok {
+		return c.ShouldRun(e)
+	}
+
+	return true
+}
+
+// Internal, shouldRun for a due system, remembers skipped systems, so they don't hold the removal log.
+func (e *ECS) shouldRunDue(s System) bool {
+	run := e.shouldRun(s)
+
+	if run {
+		delete(e.SystemStore.skipped, s.Type())
+	} else {
+		e.SystemStore.skipped[s.Type()] = true
+	}
+
+	return run
+}
+
+// Internal, returns systems that should run, keeps the order.
+func (e *ECS) runnable(systems []System) []System {
+	run := make([]System, 0, len(systems))
+
+	for _, s := range systems {
+		if e.shouldRunDue(s) {
+			run = append(run, s)
+		}
+	}
+
+	return run
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"slices"
+	"sync"
+)
+
+// Internal, splits systems into consecutive batches of non-conflicting systems, keeps the original order between batches.
+func makeSystemBatches(systems []System) [][]System {
+	batches := make([][]System, 0)
+	batch := make([]System, 0)
+
+	for _, s := range systems {
+		conflicts := slices.ContainsFunc(batch, func(other System) bool {
+			return systemsConflict(s, other)
+		})
+
+		if conflicts {
+			batches = append(batches, batch)
+			batch = make([]System, 0)
+		}
+
+		batch = append(batch, s)
+	}
+
+	if len(batch) != 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+// Internal, returns true if systems can't run at the same time.
+// Systems conflict if one writes a component type the other one reads or writes, or if any of them doesn't declare its access.
+// Systems from different stages or with ordering constraints between them conflict too.
+func systemsConflict(a, b System) bool {
+	if systemsOrdered(a, b) {
+		return true
+	}
+
+	aAccess, ok := a.(SystemWithAccess)
+
+	if !ok {
+		return true
+	}
+
+	bAccess, ok := b.(SystemWithAccess)
+
+	if !ok {
+		return true
+	}
+
+	return hasCommonTypes(aAccess.Writes(), bAccess.Writes()) ||
+		hasCommonTypes(aAccess.Writes(), bAccess.Reads()) ||
+		hasCommonTypes(aAccess.Reads(), bAccess.Writes())
+}
+
+// Internal, returns true if lists share at least one type.
+func hasCommonTypes(a, b []string) bool {
+	for _, t := range a {
+		if slices.Contains(b, t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Internal, runs fn for every system on a pool of workers and waits for all of them.
+func runOnWorkers(systems []System, workers int, fn func(s System)) {
+	if workers > len(systems) {
+		workers = len(systems)
+	}
+
+	if workers <= 1 {
+		for _, s := range systems {
+			fn(s)
+		}
+
+		return
+	}
+
+	queue := make(chan System, len(systems))
+
+	for _, s := range systems {
+		queue <- s
+	}
+
+	close(queue)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			for s := range queue {
+				fn(s)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
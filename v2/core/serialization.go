@@ -0,0 +1,551 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// Returned when loading into a store that already has entities.
+var ErrStoreNotEmpty = errors.New("entity store is not empty")
+
+// Returned when saved data can't be restored.
+var ErrInvalidSave = errors.New("invalid saved data")
+
+// Magic header of the binary format, the last byte is the format version.
+var binarySaveHeader = []byte{'E', 'C', 'S', 1}
+
+// Max number of entity slots, entity indices are 32 bit.
+const maxSavedSlots = 1 << 32
+
+// Max length of a single binary chunk (type name or component payload), protects from corrupted lengths.
+const maxBinaryChunk = 64 << 20
+
+// Internal, an entity with its components prepared for saving or restoring.
+type _SavedEntity struct {
+	id         EntityID
+	components []Component
+	children   []EntityID
+	tags       []string
+}
+
+// Internal JSON representation of the store.
+type _JSONStore struct {
+	MaxId       uint64        `json:"maxId"`
+	Generations []uint32      `json:"generations"`
+	Entities    []_JSONEntity `json:"entities"`
+}
+
+// Internal JSON representation of an entity, components are keyed by type.
+type _JSONEntity struct {
+	Id         uint64                     `json:"id"`
+	Components map[string]json.RawMessage `json:"components"`
+	Children   []uint64                   `json:"children,omitempty"`
+	Tags       []string                   `json:"tags,omitempty"`
+}
+
+// Writes all entities, their IDs, components, tags & children to w as JSON.
+// Components are encoded with encoding/json, restoring requires registered component types (see RegisterComponent).
+func (es *EntityStore) SaveJSON(w io.Writer) error {
+	maxId, generations, entities := es.collectSaved()
+
+	data := _JSONStore{
+		MaxId:       uint64(maxId),
+		Generations: generations,
+		Entities:    make([]_JSONEntity, 0, len(entities)),
+	}
+
+	for _, e := range entities {
+		je := _JSONEntity{
+			Id:         uint64(e.id),
+			Components: make(map[string]json.RawMessage, len(e.components)),
+			Tags:       e.tags,
+		}
+
+		for _, child := range e.children {
+			je.Children = append(je.Children, uint64(child))
+		}
+
+		for _, c := range e.components {
+			raw, err := json.Marshal(c)
+
+			if err != nil {
+				return fmt.Errorf("component %s of entity %d: %w", c.Type(), e.id, err)
+			}
+
+			je.Components[c.Type()] = raw
+		}
+
+		data.Entities = append(data.Entities, je)
+	}
+
+	return json.NewEncoder(w).Encode(data)
+}
+
+// Restores entities saved with SaveJSON, preserves entity IDs. The store should be empty.
+// Component hooks & observers are called for restored components.
+func (es *EntityStore) LoadJSON(r io.Reader) error {
+	var data _JSONStore
+
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSave, err)
+	}
+
+	entities := make([]_SavedEntity, 0, len(data.Entities))
+
+	for _, je := range data.Entities {
+		e := _SavedEntity{
+			id:         EntityID(je.Id),
+			components: make([]Component, 0, len(je.Components)),
+			tags:       je.Tags,
+		}
+
+		for _, child := range je.Children {
+			e.children = append(e.children, EntityID(child))
+		}
+
+		// sorted, so components are attached in the same order every time
+		for _, cType := range slices.Sorted(maps.Keys(je.Components)) {
+			raw := je.Components[cType]
+			c, err := NewComponent(cType)
+
+			if err != nil {
+				return err
+			}
+
+			if err := json.Unmarshal(raw, c); err != nil {
+				return fmt.Errorf("%w: component %s of entity %d: %w", ErrInvalidSave, cType, je.Id, err)
+			}
+
+			e.components = append(e.components, c)
+		}
+
+		entities = append(entities, e)
+	}
+
+	return es.restore(EntityID(data.MaxId), data.Generations, entities)
+}
+
+// Writes all entities, their IDs, components, tags & children to w in a compact binary format.
+// Components implementing encoding.BinaryMarshaler are encoded with it, others with encoding/json.
+func (es *EntityStore) SaveBinary(w io.Writer) error {
+	maxId, generations, entities := es.collectSaved()
+
+	// component types table, components refer to types by index
+	typeIndex := make(map[ComponentType]uint64)
+	types := make([]ComponentType, 0)
+
+	for _, e := range entities {
+		for _, c := range e.components {
+			if _, ok := typeIndex[c.Type()]; !ok {
+				typeIndex[c.Type()] = uint64(len(types))
+				types = append(types, c.Type())
+			}
+		}
+	}
+
+	buf := make([]byte, 0, 1024)
+	buf = append(buf, binarySaveHeader...)
+	buf = binary.AppendUvarint(buf, uint64(maxId))
+
+	buf = binary.AppendUvarint(buf, uint64(len(generations)))
+	for _, g := range generations {
+		buf = binary.AppendUvarint(buf, uint64(g))
+	}
+
+	buf = binary.AppendUvarint(buf, uint64(len(types)))
+	for _, t := range types {
+		buf = appendBytes(buf, []byte(t))
+	}
+
+	buf = binary.AppendUvarint(buf, uint64(len(entities)))
+	for _, e := range entities {
+		buf = binary.AppendUvarint(buf, uint64(e.id))
+		buf = binary.AppendUvarint(buf, uint64(len(e.components)))
+
+		for _, c := range e.components {
+			payload, err := marshalComponent(c)
+
+			if err != nil {
+				return fmt.Errorf("component %s of entity %d: %w", c.Type(), e.id, err)
+			}
+
+			buf = binary.AppendUvarint(buf, typeIndex[c.Type()])
+			buf = appendBytes(buf, payload)
+		}
+
+		buf = binary.AppendUvarint(buf, uint64(len(e.children)))
+		for _, child := range e.children {
+			buf = binary.AppendUvarint(buf, uint64(child))
+		}
+
+		buf = binary.AppendUvarint(buf, uint64(len(e.tags)))
+		for _, tag := range e.tags {
+			buf = appendBytes(buf, []byte(tag))
+		}
+	}
+
+	_, err := w.Write(buf)
+	return err
+}
+
+// Restores entities saved with SaveBinary, preserves entity IDs. The store should be empty.
+// Component hooks & observers are called for restored components.
+func (es *EntityStore) LoadBinary(r io.Reader) error {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, len(binarySaveHeader))
+
+	if _, err := io.ReadFull(br, header); err != nil || !bytes.Equal(header, binarySaveHeader) {
+		return fmt.Errorf("%w: bad header", ErrInvalidSave)
+	}
+
+	maxId, err := binary.ReadUvarint(br)
+	if err != nil {
+		return invalidSaveError(err)
+	}
+
+	genCount, err := binary.ReadUvarint(br)
+	if err != nil {
+		return invalidSaveError(err)
+	}
+
+	if err := validSlotCounts(maxId, genCount); err != nil {
+		return err
+	}
+
+	// grows while reading, so corrupted counts fail on EOF instead of allocating
+	generations := make([]uint32, 0)
+	for range genCount {
+		g, err := binary.ReadUvarint(br)
+		if err != nil {
+			return invalidSaveError(err)
+		}
+
+		generations = append(generations, uint32(g))
+	}
+
+	typeCount, err := binary.ReadUvarint(br)
+	if err != nil {
+		return invalidSaveError(err)
+	}
+
+	types := make([]ComponentType, 0)
+	for range typeCount {
+		t, err := readBytes(br)
+		if err != nil {
+			return invalidSaveError(err)
+		}
+
+		types = append(types, string(t))
+	}
+
+	entityCount, err := binary.ReadUvarint(br)
+	if err != nil {
+		return invalidSaveError(err)
+	}
+
+	if entityCount > maxId {
+		return fmt.Errorf("%w: %d entities for %d slots", ErrInvalidSave, entityCount, maxId)
+	}
+
+	entities := make([]_SavedEntity, 0)
+	for range entityCount {
+		id, err := binary.ReadUvarint(br)
+		if err != nil {
+			return invalidSaveError(err)
+		}
+
+		compCount, err := binary.ReadUvarint(br)
+		if err != nil {
+			return invalidSaveError(err)
+		}
+
+		e := _SavedEntity{id: EntityID(id)}
+
+		for range compCount {
+			index, err := binary.ReadUvarint(br)
+			if err != nil {
+				return invalidSaveError(err)
+			}
+
+			if index >= uint64(len(types)) {
+				return fmt.Errorf("%w: component type index %d is out of range", ErrInvalidSave, index)
+			}
+
+			payload, err := readBytes(br)
+			if err != nil {
+				return invalidSaveError(err)
+			}
+
+			c, err := unmarshalComponent(types[index], payload)
+			if err != nil {
+				return err
+			}
+
+			e.components = append(e.components, c)
+		}
+
+		childCount, err := binary.ReadUvarint(br)
+		if err != nil {
+			return invalidSaveError(err)
+		}
+
+		for range childCount {
+			child, err := binary.ReadUvarint(br)
+			if err != nil {
+				return invalidSaveError(err)
+			}
+
+			e.children = append(e.children, EntityID(child))
+		}
+
+		tagCount, err := binary.ReadUvarint(br)
+		if err != nil {
+			return invalidSaveError(err)
+		}
+
+		for range tagCount {
+			tag, err := readBytes(br)
+			if err != nil {
+				return invalidSaveError(err)
+			}
+
+			e.tags = append(e.tags, string(tag))
+		}
+
+		entities = append(entities, e)
+	}
+
+	return es.restore(EntityID(maxId), generations, entities)
+}
+
+// Internal, returns slots count, slot generations & alive entities ordered by slot index.
+func (es *EntityStore) collectSaved() (EntityID, []uint32, []_SavedEntity) {
+	es.readLock()
+	defer es.readUnlock()
+
+	generations := make([]uint32, 0, len(es.slots))
+	entities := make([]_SavedEntity, 0, es.alive)
+
+	for i, slot := range es.slots {
+		generations = append(generations, slot.generation)
+
+		if !slot.alive {
+			continue
+		}
+
+		id := MakeEntityID(uint32(i), slot.generation)
+
+		entities = append(entities, _SavedEntity{
+			id:         id,
+			components: slot.loc.arch.entities[slot.loc.row].getAll(slot.loc),
+			children:   slices.Clone(es.children[id]),
+			tags:       es.tagNames(slot.tags),
+		})
+	}
+
+	// reserved but never spawned IDs have no slots, saved as unused ones
+	for EntityID(len(generations)) < es.maxId {
+		generations = append(generations, 0)
+	}
+
+	return es.maxId, generations, entities
+}
+
+// Internal, restores slots & entities into an empty store.
+// Saved data is validated before anything is restored, so invalid data leaves the store empty.
+func (es *EntityStore) restore(maxId EntityID, generations []uint32, entities []_SavedEntity) error {
+	if err := validSlotCounts(uint64(maxId), uint64(len(generations))); err != nil {
+		return err
+	}
+
+	if uint64(len(entities)) > uint64(maxId) {
+		return fmt.Errorf("%w: %d entities for %d slots", ErrInvalidSave, len(entities), maxId)
+	}
+
+	seen := make(map[uint32]bool, len(entities))
+
+	for _, e := range entities {
+		if uint64(e.id.Index()) >= uint64(maxId) {
+			return fmt.Errorf("%w: entity index %d exceeds max ID %d", ErrInvalidSave, e.id.Index(), maxId)
+		}
+
+		if seen[e.id.Index()] {
+			return fmt.Errorf("%w: duplicate entity index %d", ErrInvalidSave, e.id.Index())
+		}
+
+		seen[e.id.Index()] = true
+	}
+
+	if err := validSavedChildren(entities); err != nil {
+		return err
+	}
+
+	es.writeLock()
+
+	if es.alive != 0 || es.maxId != 0 {
+		es.writeUnlock()
+		return ErrStoreNotEmpty
+	}
+
+	if maxId == 0 {
+		es.writeUnlock()
+		return nil
+	}
+
+	es.maxId = maxId
+	es.ensureSlot(uint32(maxId) - 1)
+
+	for i, g := range generations {
+		es.slots[i].generation = g
+	}
+
+	// free slots, the lowest index is reused first
+	for i := uint32(maxId); i > 0; i-- {
+		if !seen[i-1] {
+			es.freeList = append(es.freeList, i-1)
+		}
+	}
+
+	es.writeUnlock()
+
+	for _, e := range entities {
+		es.spawn(e.id, e.components...)
+		es.Tag(e.id, e.tags...)
+	}
+
+	for _, e := range entities {
+		for _, child := range e.children {
+			if err := es.SetParent(child, e.id); err != nil {
+				return fmt.Errorf("%w: child %d of entity %d: %w", ErrInvalidSave, child, e.id, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Internal, checks the saved slots count, every slot has a saved generation.
+func validSlotCounts(maxId uint64, generations uint64) error {
+	if maxId > maxSavedSlots {
+		return fmt.Errorf("%w: max ID %d exceeds %d", ErrInvalidSave, maxId, uint64(maxSavedSlots))
+	}
+
+	if generations != maxId {
+		return fmt.Errorf("%w: %d generations for %d slots", ErrInvalidSave, generations, maxId)
+	}
+
+	return nil
+}
+
+// Internal, checks that children are saved entities with a single parent & without cycles.
+func validSavedChildren(entities []_SavedEntity) error {
+	saved := make(map[EntityID]bool, len(entities))
+	parents := make(map[EntityID]EntityID)
+
+	for _, e := range entities {
+		saved[e.id] = true
+	}
+
+	for _, e := range entities {
+		for _, child := range e.children {
+			if !saved[child] {
+				return fmt.Errorf("%w: child %d of entity %d is not saved", ErrInvalidSave, child, e.id)
+			}
+
+			if _, ok := parents[child]; ok {
+				return fmt.Errorf("%w: child %d has several parents", ErrInvalidSave, child)
+			}
+
+			parents[child] = e.id
+		}
+	}
+
+	// walks up from each entity, ancestors of checked entities are not walked again
+	checked := make(map[EntityID]bool, len(entities))
+
+	for _, e := range entities {
+		path := make(map[EntityID]bool)
+
+		for id, ok := e.id, true; ok && !checked[id]; id, ok = parents[id] {
+			if path[id] {
+				return fmt.Errorf("%w: entity %d: %w", ErrInvalidSave, id, ErrRelationCycle)
+			}
+
+			path[id] = true
+		}
+
+		for id := range path {
+			checked[id] = true
+		}
+	}
+
+	return nil
+}
+
+// Internal, encodes a component with encoding.BinaryMarshaler or JSON.
+func marshalComponent(c Component) ([]byte, error) {
+	if m, ok := c.(encoding.BinaryMarshaler); ok {
+		return m.MarshalBinary()
+	}
+
+	return json.Marshal(c)
+}
+
+// Internal, creates a registered component & decodes it with encoding.BinaryUnmarshaler or JSON.
+func unmarshalComponent(componentType ComponentType, payload []byte) (Component, error) {
+	c, err := NewComponent(componentType)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if u, ok := c.(encoding.BinaryUnmarshaler); ok {
+		err = u.UnmarshalBinary(payload)
+	} else {
+		err = json.Unmarshal(payload, c)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: component %s: %w", ErrInvalidSave, componentType, err)
+	}
+
+	return c, nil
+}
+
+// Internal, appends length prefixed bytes.
+func appendBytes(buf []byte, data []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(data)))
+	return append(buf, data...)
+}
+
+// Internal, reads length prefixed bytes.
+func readBytes(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if n > maxBinaryChunk {
+		return nil, fmt.Errorf("chunk length %d exceeds %d", n, maxBinaryChunk)
+	}
+
+	data := make([]byte, n)
+	_, err = io.ReadFull(r, data)
+
+	return data, err
+}
+
+// Internal, wraps a read error with ErrInvalidSave.
+func invalidSaveError(err error) error {
+	return fmt.Errorf("%w: %w", ErrInvalidSave, err)
+}
@@ -0,0 +1,82 @@
+package core
+
+// Returns the numeric ID of a component type in the store, false if the type was never used in the store.
+// IDs are assigned on first use (attachment, query or observer registration), archetype signatures are bitsets of them.
+func (es *EntityStore) ComponentID(componentType string) (int, bool) {
+	es.readLock()
+	defer es.readUnlock()
+
+	id, ok := es.componentIds[componentType]
+	return id, ok
+}
+
+// Internal, returns the component type ID, assigns the next one on first use. The store must be locked for writing.
+func (es *EntityStore) componentId(componentType ComponentType) int {
+	if id, ok := es.componentIds[componentType]; ok {
+		return id
+	}
+
+	id := len(es.componentList)
+	es.componentIds[componentType] = id
+	es.componentList = append(es.componentList, componentType)
+
+	return id
+}
+
+// Internal, returns type IDs of provided components, -1 for never used types.
+func (es *EntityStore) componentIdsOf(components []Component) []int {
+	ids := make([]int, len(components))
+
+	for i, c := range components {
+		if id, ok := es.componentIds[c.Type()]; ok {
+			ids[i] = id
+		} else {
+			ids[i] = -1
+		}
+	}
+
+	return ids
+}
+
+// Internal, returns a signature of provided types, false if some type was never used in the store.
+func (es *EntityStore) componentMask(componentTypes []ComponentType) (bitset, bool) {
+	var mask bitset
+
+	for _, t := range componentTypes {
+		id, ok := es.componentIds[t]
+
+		if !ok {
+			return nil, false
+		}
+
+		mask.set(id)
+	}
+
+	return mask, true
+}
+
+// Internal, returns a signature of provided types, never used types are skipped.
+func (es *EntityStore) knownComponentMask(componentTypes []ComponentType) bitset {
+	var mask bitset
+
+	for _, t := range componentTypes {
+		if id, ok := es.componentIds[t]; ok {
+			mask.set(id)
+		}
+	}
+
+	return mask
+}
+
+// Internal, returns true if the archetype signature contains all provided types, doesn't allocate.
+func (es *EntityStore) signatureHas(a *archetype, componentTypes []ComponentType) bool {
+	for _, t := range componentTypes {
+		id, ok := es.componentIds[t]
+
+		if !ok || !a.signature.has(id) {
+			return false
+		}
+	}
+
+	return true
+}
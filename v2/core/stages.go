@@ -0,0 +1,173 @@
+package core
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Named group of systems, stages are processed in the declaration order.
+type Stage int
+
+const (
+	StagePreUpdate Stage = iota
+	// Default stage for systems that don't implement SystemWithOrdering.
+	StageUpdate
+	StagePostUpdate
+	StageRender
+)
+
+// Returned by SystemStore.Add when Before/After constraints or stages form a cycle.
+var ErrSystemCycle = errors.New("system ordering cycle")
+
+// System that declares its stage & ordering constraints relative to other system types.
+// Constraints referencing systems that are not added are ignored, priority orders systems that are not constrained.
+type SystemWithOrdering interface {
+	System
+
+	// Returns the stage the system belongs to.
+	Stage() Stage
+	// Returns system types that must be processed after the system.
+	Before() []string
+	// Returns system types that must be processed before the system.
+	After() []string
+}
+
+// Returns a human readable stage name.
+func (s Stage) String() string {
+	switch s {
+	case StagePreUpdate:
+		return "PreUpdate"
+	case StageUpdate:
+		return "Update"
+	case StagePostUpdate:
+		return "PostUpdate"
+	case StageRender:
+		return "Render"
+	}
+
+	return fmt.Sprintf("Stage(%d)", int(s))
+}
+
+// Internal, returns the system stage, StageUpdate for systems without ordering.
+func systemStage(s System) Stage {
+	if o, ok := s.(SystemWithOrdering); ok {
+		return o.Stage()
+	}
+
+	return StageUpdate
+}
+
+// Internal, returns true if systems are ordered relative to each other by stages or constraints.
+func systemsOrdered(a, b System) bool {
+	if systemStage(a) != systemStage(b) {
+		return true
+	}
+
+	if o, ok := a.(SystemWithOrdering); ok && (slices.Contains(o.Before(), b.Type()) || slices.Contains(o.After(), b.Type())) {
+		return true
+	}
+
+	if o, ok := b.(SystemWithOrdering); ok && (slices.Contains(o.Before(), a.Type()) || slices.Contains(o.After(), a.Type())) {
+		return true
+	}
+
+	return false
+}
+
+// Internal, sorts systems by stage, ordering constraints & priority (topological sort).
+// Ties are resolved by higher priority, then by the adding order. Returns ErrSystemCycle if constraints can't be satisfied.
+func sortSystems(systems map[string]System, added []string) ([]_SystemPriority, error) {
+	addIndex := make(map[string]int, len(added))
+	edges := make(map[string][]string, len(added))
+	inDegree := make(map[string]int, len(added))
+
+	for i, sysType := range added {
+		addIndex[sysType] = i
+	}
+
+	addEdge := func(from, to string) error {
+		if systems[from] == nil || systems[to] == nil {
+			return nil
+		}
+
+		// constraints against the stage order can't be satisfied
+		if systemStage(systems[from]) > systemStage(systems[to]) {
+			return fmt.Errorf("%w: %s must run before %s, but its stage is later", ErrSystemCycle, from, to)
+		}
+
+		edges[from] = append(edges[from], to)
+		inDegree[to]++
+		return nil
+	}
+
+	for _, sysType := range added {
+		o, ok := systems[sysType].(SystemWithOrdering)
+
+		if !ok {
+			continue
+		}
+
+		for _, other := range o.Before() {
+			if err := addEdge(sysType, other); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, other := range o.After() {
+			if err := addEdge(other, sysType); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	compare := func(a, b string) int {
+		sa, sb := systems[a], systems[b]
+
+		return cmp.Or(
+			cmp.Compare(systemStage(sa), systemStage(sb)),
+			cmp.Compare(sb.Priority(), sa.Priority()),
+			cmp.Compare(addIndex[a], addIndex[b]),
+		)
+	}
+
+	ready := make([]string, 0)
+
+	for _, sysType := range added {
+		if inDegree[sysType] == 0 {
+			ready = append(ready, sysType)
+		}
+	}
+
+	sorted := make([]_SystemPriority, 0, len(added))
+
+	for len(ready) != 0 {
+		next := slices.MinFunc(ready, compare)
+		ready = slices.DeleteFunc(ready, func(t string) bool { return t == next })
+		sorted = append(sorted, makeSystemPriority(systems[next]))
+
+		for _, to := range edges[next] {
+			inDegree[to]--
+
+			if inDegree[to] == 0 {
+				ready = append(ready, to)
+			}
+		}
+	}
+
+	if len(sorted) != len(added) {
+		cycle := make([]string, 0)
+
+		for _, sysType := range added {
+			if inDegree[sysType] > 0 {
+				cycle = append(cycle, sysType)
+			}
+		}
+
+		return nil, fmt.Errorf("%w: %s", ErrSystemCycle, strings.Join(cycle, ", "))
+	}
+
+	return sorted, nil
+}
@@ -0,0 +1,128 @@
+package core
+
+import "sync"
+
+// Called by the state machine when a state is entered or exited.
+type StateHook func(ecs *ECS)
+
+// Application state machine (MainMenu -> Loading -> Playing -> Paused...), states are plain strings.
+// Transitions are queued & applied by ECS at the start of the next Process (or Step): OnExit hooks
+// of the current state run first, then OnEnter hooks of the next one. Safe to use from systems running in parallel.
+type StateMachine struct {
+	current string
+	next    string
+	pending bool
+
+	onEnter map[string][]StateHook
+	onExit  map[string][]StateHook
+
+	lock sync.Mutex
+}
+
+// State machine constructor, the initial state is empty (no state).
+func MakeStateMachine() *StateMachine {
+	return &StateMachine{
+		onEnter: make(map[string][]StateHook),
+		onExit:  make(map[string][]StateHook),
+	}
+}
+
+// Returns the current state, empty if no state was entered yet.
+func (sm *StateMachine) Current() string {
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+
+	return sm.current
+}
+
+// Returns the queued state, ok is false if no transition is queued.
+func (sm *StateMachine) Next() (string, bool) {
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+
+	return sm.next, sm.pending
+}
+
+// Queues a transition to provided state, replaces the previously queued one.
+// Queuing the current state cancels the queued transition.
+func (sm *StateMachine) Set(state string) {
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+
+	sm.next = state
+	sm.pending = state != sm.current
+}
+
+// Adds a hook called when provided state is entered.
+func (sm *StateMachine) OnEnter(state string, hook StateHook) {
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+
+	sm.onEnter[state] = append(sm.onEnter[state], hook)
+}
+
+// Adds a hook called when provided state is exited.
+func (sm *StateMachine) OnExit(state string, hook StateHook) {
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+
+	sm.onExit[state] = append(sm.onExit[state], hook)
+}
+
+// Run condition that passes while the current state is one of provided states.
+func InState(states ...string) RunCondition {
+	return func(ecs *ECS) bool {
+		current := ecs.States.Current()
+
+		for _, s := range states {
+			if s == current {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Adds a system processed only while the current state is one of provided states, see SystemStore.Add.
+func (ss *SystemStore) AddInState(system System, states ...string) error {
+	if err := ss.Add(system); err != nil {
+		return err
+	}
+
+	ss.AddRunCondition(system.Type(), InState(states...))
+	return nil
+}
+
+// Internal, applies the queued state transition, hooks may queue the next transition for the next frame.
+// Returns errors of failed commands recorded by hooks.
+func (e *ECS) applyStateTransition() error {
+	sm := e.States
+	sm.lock.Lock()
+
+	if !sm.pending || sm.next == sm.current {
+		sm.pending = false
+		sm.lock.Unlock()
+		return nil
+	}
+
+	prev, next := sm.current, sm.next
+	exit, enter := sm.onExit[prev], sm.onEnter[next]
+	sm.pending = false
+	sm.lock.Unlock()
+
+	// hooks are called unlocked, so they can use the state machine
+	for _, hook := range exit {
+		hook(e)
+	}
+
+	sm.lock.Lock()
+	sm.current = next
+	sm.lock.Unlock()
+
+	for _, hook := range enter {
+		hook(e)
+	}
+
+	return e.EntityStore.Commands().Apply()
+}
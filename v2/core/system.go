@@ -32,6 +32,17 @@ type SystemWithObservableHooks interface {
 	OnComponentDetached(componentType string, entity Entity)
 }
 
+// System that declares accessed component types, so the scheduler can run it in parallel with non-conflicting systems.
+// Systems without declared access always run alone.
+type SystemWithAccess interface {
+	System
+
+	// Returns component types the system only reads.
+	Reads() []string
+	// Returns component types the system writes.
+	Writes() []string
+}
+
 // SystemBase implements the System interface but not includes Process.
 // It can be used to reduce boilerplate code, override only the methods you need.
 type SystemBase struct {
@@ -23,15 +23,30 @@ func (p *_SystemPriority) GetSystemType() string {
 	return p.system
 }
 
-// Manages all systems according to their priority & process frequency.
+// Manages all systems according to their stage, ordering constraints, priority & process frequency.
 type SystemStore struct {
 	systems map[string]System
 
-	// Priorities from high to low.
+	// Systems in the execution order.
 	priority []_SystemPriority
+	// System types in the adding order, resolves ties between equal systems.
+	added []string
 
 	// Time from last system process.
 	lastCallTime map[string]time.Time
+
+	// Time source for last call times.
+	clock Clock
+
+	// World tick of the last system process, used for change detection.
+	lastRunTick map[string]uint64
+
+	// Disabled system types & run conditions by system type.
+	disabled   map[string]bool
+	conditions map[string][]RunCondition
+
+	// Systems skipped by run conditions when they were due the last time.
+	skipped map[string]bool
 }
 
 // System store constructor.
@@ -39,30 +54,42 @@ func MakeSystemStore() *SystemStore {
 	return &SystemStore{
 		systems:      make(map[string]System),
 		priority:     make([]_SystemPriority, 0),
+		added:        make([]string, 0),
 		lastCallTime: make(map[string]time.Time),
+		clock:        RealClock{},
+		lastRunTick:  make(map[string]uint64),
+		disabled:     make(map[string]bool),
+		conditions:   make(map[string][]RunCondition),
+		skipped:      make(map[string]bool),
 	}
 }
 
 // Adds a system to the store, so it can be processed. Panics if the same system type is already added.
-func (ss *SystemStore) Add(system System) {
+// Returns ErrSystemCycle & doesn't add the system if its ordering constraints can't be satisfied (see SystemWithOrdering).
+func (ss *SystemStore) Add(system System) error {
 	// --- Checking if the system is already added
 	if _, ok := ss.systems[system.Type()]; ok {
 		panic("System " + system.Type() + " is already exists")
 	}
 
-	// --- Setting up the priority
-	ss.priority = append(ss.priority, makeSystemPriority(system))
-
-	// sort hight to low
-	slices.SortFunc(ss.priority, func(a, b _SystemPriority) int {
-		return b.priority - a.priority
-	})
-
 	// --- Adding the system
 	ss.systems[system.Type()] = system
+	ss.added = append(ss.added, system.Type())
+
+	// --- Resolving the execution order
+	sorted, err := sortSystems(ss.systems, ss.added)
+
+	if err != nil {
+		delete(ss.systems, system.Type())
+		ss.added = ss.added[:len(ss.added)-1]
+		return err
+	}
+
+	ss.priority = sorted
 
 	// Add the last call time
-	ss.lastCallTime[system.Type()] = time.Now()
+	ss.lastCallTime[system.Type()] = ss.clock.Now()
+	return nil
 }
 
 // Removes a system from the store, so it can no longer be processed.
@@ -70,7 +97,7 @@ func (ss *SystemStore) Remove(typeName string) {
 	// --- Removing the system
 	delete(ss.systems, typeName)
 
-	// --- Removing the priority
+	// --- Removing the priority, the rest order is still valid
 	for i, p := range ss.priority {
 		if p.system == typeName {
 			ss.priority = utils.ShiftRemoveI(ss.priority, i)
@@ -78,8 +105,16 @@ func (ss *SystemStore) Remove(typeName string) {
 		}
 	}
 
+	if i := slices.Index(ss.added, typeName); i != -1 {
+		ss.added = utils.ShiftRemoveI(ss.added, i)
+	}
+
 	// Remove the last call time
 	delete(ss.lastCallTime, typeName)
+	delete(ss.lastRunTick, typeName)
+	delete(ss.disabled, typeName)
+	delete(ss.conditions, typeName)
+	delete(ss.skipped, typeName)
 }
 
 // Returns a system from the store by its type. May return nil if no such system was added.
@@ -92,16 +127,45 @@ func (ss *SystemStore) GetAll() map[string]System {
 	return ss.systems
 }
 
-// Returns all systems from the store sorted ascending by priority.
+// Returns all systems from the store in the execution order.
 func (ss *SystemStore) Priority() []_SystemPriority {
 	return ss.priority
 }
 
+// Sets the clock used for last call times, resets last call times of added systems to the new clock time.
+func (ss *SystemStore) SetClock(clock Clock) {
+	ss.clock = clock
+	now := clock.Now()
+
+	for sysType := range ss.lastCallTime {
+		ss.lastCallTime[sysType] = now
+	}
+}
+
 // Returns map of last call times, key is system type, value is last call time. May be useful for debugging.
 func (ss *SystemStore) LastCallTimeMap() map[string]time.Time {
 	return ss.lastCallTime
 }
 
+// Returns the world tick when the system was processed the last time, 0 if it was never processed.
+func (ss *SystemStore) LastRunTick(typeName string) uint64 {
+	return ss.lastRunTick[typeName]
+}
+
+// Internal, returns the lowest last run tick of running systems, or provided tick if there are no such systems.
+// Disabled & skipped by run conditions systems are ignored, so they don't keep the removal log forever.
+func (ss *SystemStore) minLastRunTick(tick uint64) uint64 {
+	for sysType := range ss.systems {
+		if ss.disabled[sysType] || ss.skipped[sysType] {
+			continue
+		}
+
+		tick = min(tick, ss.lastRunTick[sysType])
+	}
+
+	return tick
+}
+
 // Internal system priority constructor.
 func makeSystemPriority(system System) _SystemPriority {
 	return _SystemPriority{
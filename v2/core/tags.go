@@ -0,0 +1,174 @@
+package core
+
+// Attaches tags to an entity. Tags are data-less markers (e.g. "enemy", "selected") stored in a per-entity bitset,
+// so they don't allocate components & don't move the entity to another archetype.
+// Tag observers (see TagObserver) observing a tag are notified with OnAttach about newly attached tags.
+// Returns ErrEntityNotAlive if the entity doesn't exist.
+func (es *EntityStore) Tag(id EntityID, tags ...string) error {
+	es.writeLock()
+	loc, ok := es.location(id)
+
+	if !ok {
+		es.writeUnlock()
+		return notAliveError(id)
+	}
+
+	slot := &es.slots[id.Index()]
+	attached := make([]string, 0, len(tags))
+	bits := make([]int, 0, len(tags))
+
+	for _, tag := range tags {
+		bit := es.tagBit(tag)
+
+		if !slot.tags.has(bit) {
+			slot.tags.set(bit)
+			attached = append(attached, tag)
+			bits = append(bits, bit)
+		}
+	}
+
+	e := loc.arch.entities[loc.row]
+	observers := es.observers
+	es.writeUnlock()
+
+	notifyTags(observers, e, attached, bits, true)
+	return nil
+}
+
+// Detaches tags from an entity, tag observers observing a tag are notified with OnDetach about detached tags.
+// Returns ErrEntityNotAlive if the entity doesn't exist.
+func (es *EntityStore) Untag(id EntityID, tags ...string) error {
+	es.writeLock()
+	loc, ok := es.location(id)
+
+	if !ok {
+		es.writeUnlock()
+		return notAliveError(id)
+	}
+
+	slot := &es.slots[id.Index()]
+	detached := make([]string, 0, len(tags))
+	bits := make([]int, 0, len(tags))
+
+	for _, tag := range tags {
+		bit, ok := es.tagIds[tag]
+
+		if ok && slot.tags.has(bit) {
+			slot.tags.unset(bit)
+			detached = append(detached, tag)
+			bits = append(bits, bit)
+		}
+	}
+
+	e := loc.arch.entities[loc.row]
+	observers := es.observers
+	es.writeUnlock()
+
+	notifyTags(observers, e, detached, bits, false)
+	return nil
+}
+
+// Returns true if the entity has all provided tags, false for removed entities.
+func (es *EntityStore) HasTag(id EntityID, tags ...string) bool {
+	es.readLock()
+	defer es.readUnlock()
+
+	if _, ok := es.location(id); !ok {
+		return false
+	}
+
+	slot := &es.slots[id.Index()]
+
+	for _, tag := range tags {
+		bit, ok := es.tagIds[tag]
+
+		if !ok || !slot.tags.has(bit) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Returns entity tags in the tag registration order, nil for removed entities.
+func (es *EntityStore) Tags(id EntityID) []string {
+	es.readLock()
+	defer es.readUnlock()
+
+	if _, ok := es.location(id); !ok {
+		return nil
+	}
+
+	return es.tagNames(es.slots[id.Index()].tags)
+}
+
+// Internal, returns the tag bit, registers the tag on first use. The store must be locked for writing.
+func (es *EntityStore) tagBit(tag string) int {
+	if bit, ok := es.tagIds[tag]; ok {
+		return bit
+	}
+
+	bit := len(es.tagList)
+	es.tagIds[tag] = bit
+	es.tagList = append(es.tagList, tag)
+
+	return bit
+}
+
+// Internal, returns a bitset of provided tags, false if some tag was never registered.
+func (es *EntityStore) tagMask(tags []string) (bitset, bool) {
+	var mask bitset
+
+	for _, tag := range tags {
+		bit, ok := es.tagIds[tag]
+
+		if !ok {
+			return nil, false
+		}
+
+		mask.set(bit)
+	}
+
+	return mask, true
+}
+
+// Internal, returns a bitset of provided tags, never used tags are skipped.
+func (es *EntityStore) knownTagMask(tags []string) bitset {
+	var mask bitset
+
+	for _, tag := range tags {
+		if bit, ok := es.tagIds[tag]; ok {
+			mask.set(bit)
+		}
+	}
+
+	return mask
+}
+
+// Internal, returns tag names of the bitset.
+func (es *EntityStore) tagNames(tags bitset) []string {
+	names := make([]string, 0)
+
+	for _, bit := range tags.members() {
+		names = append(names, es.tagList[bit])
+	}
+
+	return names
+}
+
+// Internal, notifies observers observing provided tags (with provided bits) about their attachment or detachment.
+func notifyTags(observers []observerEntry, e Entity, tags []string, bits []int, attached bool) {
+	for i, tag := range tags {
+		for _, o := range observers {
+			if !o.tags.has(bits[i]) {
+				continue
+			}
+
+			if attached {
+				o.observer.OnAttach(tag, e)
+			} else {
+				o.observer.OnDetach(tag, e)
+			}
+		}
+	}
+}
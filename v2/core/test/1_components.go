@@ -17,3 +17,11 @@ type _TestComponent2 struct {
 func (t *_TestComponent2) Type() string {
 	return "TestComponent2"
 }
+
+type _ValueComponent struct {
+	Value int
+}
+
+func (c *_ValueComponent) Type() string {
+	return "value"
+}
@@ -0,0 +1,125 @@
+package engine_test
+
+import (
+	"testing"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+func TestArchetypeMoves(t *testing.T) {
+	t.Run("Moving an entity should not corrupt other entities components", func(t *testing.T) {
+		es := MakeEntityStore()
+		ents := make([]Entity, 0)
+
+		for i := 0; i < 5; i++ {
+			ents = append(ents, es.New(&_ValueComponent{Value: i}, &_TestComponent{}))
+		}
+
+		// moves the entity from the middle of the archetype
+		ents[1].Remove("TestComponent")
+		es.Remove(ents[2].Id())
+
+		for i, e := range ents {
+			if i == 2 {
+				continue
+			}
+
+			val := (*e.GetOne("value")).(*_ValueComponent)
+
+			if val.Value != i {
+				t.Errorf("Expected entity %d value to be %d, got %d", e.Id(), i, val.Value)
+			}
+		}
+
+		if ents[1].Has("TestComponent") {
+			t.Errorf("Expected entity %d not to have TestComponent", ents[1].Id())
+		}
+
+		if !ents[3].Has("value", "TestComponent") {
+			t.Errorf("Expected entity %d to have value & TestComponent", ents[3].Id())
+		}
+	})
+
+	t.Run("Entity should keep components after moving back & forth", func(t *testing.T) {
+		es := MakeEntityStore()
+		e := es.New(&_ValueComponent{Value: 42})
+
+		e.Add(&_TestComponent{})
+		e.Remove("TestComponent")
+		e.Add(&_TestComponent2{})
+
+		val := (*e.GetOne("value")).(*_ValueComponent)
+
+		if val.Value != 42 {
+			t.Errorf("Expected value to be 42, got %d", val.Value)
+		}
+
+		if len(e.GetAll()) != 2 {
+			t.Errorf("Expected 2 components, got %d", len(e.GetAll()))
+		}
+	})
+
+	t.Run("GetAll should not panic after removals", func(t *testing.T) {
+		es := MakeEntityStore()
+
+		for i := 0; i < 4; i++ {
+			es.New()
+		}
+
+		es.Remove(0)
+		es.Remove(1)
+
+		if len(es.GetAll()) != 2 {
+			t.Errorf("Expected 2 entities, got %d", len(es.GetAll()))
+		}
+	})
+}
+
+// Counts detach calls seen while the entity still had all observed types.
+type _CompleteDetachObserver struct {
+	_RecordingObserver
+	Complete int
+}
+
+func (o *_CompleteDetachObserver) OnDetach(cType string, e Entity) {
+	if e.Has(o.types...) {
+		o.Complete++
+	}
+}
+
+func TestArchetypeRemoval(t *testing.T) {
+	t.Run("Remove should detach all components at once", func(t *testing.T) {
+		es := MakeEntityStore()
+		comps := makeNamedComponents(5)
+
+		observer := &_CompleteDetachObserver{}
+		observer.SetObservedTypes("named_0", "named_1", "named_2", "named_3", "named_4")
+		es.AddObserver(observer)
+
+		e := es.New(comps...)
+		other := es.New(&_ValueComponent{Value: 7})
+		es.Remove(e.Id())
+
+		if observer.Complete != 5 {
+			t.Errorf("Expected all components to be attached during detach, got %d of 5", observer.Complete)
+		}
+
+		if es.Alive(e.Id()) || (*other.GetOne("value")).(*_ValueComponent).Value != 7 {
+			t.Errorf("Expected only the removed entity to be affected")
+		}
+	})
+
+	t.Run("RemoveFrom should skip missing & repeated types", func(t *testing.T) {
+		es := MakeEntityStore()
+		observer := &_RecordingObserver{}
+		observer.SetObservedTypes("value", "TestComponent")
+		es.AddObserver(observer)
+
+		e := es.New(&_ValueComponent{}, &_TestComponent{}, &_TestComponent2{})
+		e.Remove("value", "value", "TestComponent", "missing")
+
+		if observer.Detached != 2 || !e.Has("TestComponent2") || len(e.GetAll()) != 1 {
+			t.Errorf("Expected 2 detached components, got %d", observer.Detached)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package engine_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+// Counts per-entity observer calls.
+type _RecordingObserver struct {
+	types []string
+
+	Attached      int
+	Detached      int
+	AttachBatches []int
+	DetachBatches []int
+}
+
+func (o *_RecordingObserver) GetObservedTypes() []string       { return o.types }
+func (o *_RecordingObserver) SetObservedTypes(types ...string) { o.types = types }
+func (o *_RecordingObserver) OnAttach(cType string, e Entity)  { o.Attached++ }
+func (o *_RecordingObserver) OnDetach(cType string, e Entity)  { o.Detached++ }
+
+// Records batch sizes, implements BatchObserver.
+type _RecordingBatchObserver struct {
+	_RecordingObserver
+}
+
+func (o *_RecordingBatchObserver) OnAttachBatch(cType string, entities []Entity) {
+	o.AttachBatches = append(o.AttachBatches, len(entities))
+}
+
+func (o *_RecordingBatchObserver) OnDetachBatch(cType string, entities []Entity) {
+	o.DetachBatches = append(o.DetachBatches, len(entities))
+}
+
+func TestNewBatch(t *testing.T) {
+	t.Run("NewBatch should create entities with factory components", func(t *testing.T) {
+		es := MakeEntityStore()
+
+		entities := es.NewBatch(100, func(i int) []Component {
+			return []Component{&_ValueComponent{Value: i}}
+		})
+
+		if len(entities) != 100 || len(es.GetAll()) != 100 {
+			t.Fatalf("Expected 100 entities, got %d", len(es.GetAll()))
+		}
+
+		val, _ := Get[*_ValueComponent](entities[42])
+
+		if val.Value != 42 {
+			t.Errorf("Expected value 42, got %d", val.Value)
+		}
+	})
+
+	t.Run("NewBatch should notify batch observers once per type", func(t *testing.T) {
+		es := MakeEntityStore()
+		batch := &_RecordingBatchObserver{}
+		single := &_RecordingObserver{}
+
+		batch.SetObservedTypes("value")
+		single.SetObservedTypes("value")
+		es.AddObserver(batch)
+		es.AddObserver(single)
+
+		es.NewBatch(10, func(i int) []Component {
+			return []Component{&_ValueComponent{Value: i}}
+		})
+
+		if len(batch.AttachBatches) != 1 || batch.AttachBatches[0] != 10 || batch.Attached != 0 {
+			t.Errorf("Expected a single batch of 10, got %v", batch.AttachBatches)
+		}
+
+		if single.Attached != 10 {
+			t.Errorf("Expected 10 single notifications, got %d", single.Attached)
+		}
+	})
+}
+
+func TestAddToMany(t *testing.T) {
+	t.Run("AddToMany should attach copies of components", func(t *testing.T) {
+		es := MakeEntityStore()
+		a := es.New()
+		b := es.New()
+
+		es.AddToMany([]EntityID{a.Id(), b.Id()}, &_ValueComponent{Value: 1})
+
+		valA, _ := Get[*_ValueComponent](a)
+		valB, _ := Get[*_ValueComponent](b)
+		valA.Value = 2
+
+		if valB.Value != 1 {
+			t.Errorf("Expected entities not to share components")
+		}
+	})
+
+	t.Run("AddToMany should skip removed entities", func(t *testing.T) {
+		es := MakeEntityStore()
+		a := es.New()
+		b := es.New()
+		es.Remove(b.Id())
+
+		err := es.AddToMany([]EntityID{a.Id(), b.Id()}, &_TestComponent{})
+
+		if !errors.Is(err, ErrEntityNotAlive) || !a.Has("TestComponent") {
+			t.Errorf("Expected ErrEntityNotAlive & alive entity to be changed, got %v", err)
+		}
+	})
+}
+
+func TestRemoveAll(t *testing.T) {
+	t.Run("RemoveAll should detach the component from all entities", func(t *testing.T) {
+		es := MakeEntityStore()
+		observer := &_RecordingBatchObserver{}
+		observer.SetObservedTypes("component_with_hooks")
+		es.AddObserver(observer)
+
+		hooks := make([]*_ComponentWithHooks, 0)
+
+		for i := 0; i < 5; i++ {
+			c := &_ComponentWithHooks{}
+			hooks = append(hooks, c)
+
+			if i%2 == 0 {
+				es.New(c, &_TestComponent{})
+			} else {
+				es.New(c)
+			}
+		}
+
+		if n := es.RemoveAll("component_with_hooks"); n != 5 {
+			t.Errorf("Expected 5 changed entities, got %d", n)
+		}
+
+		if len(MakeFinder(es).Has("component_with_hooks").GetMany()) != 0 || len(es.GetAll()) != 5 {
+			t.Errorf("Expected components to be removed & entities to stay alive")
+		}
+
+		for _, c := range hooks {
+			if !c.OnDetachIsCalled {
+				t.Errorf("Expected OnDetach to be called")
+			}
+		}
+
+		if len(observer.DetachBatches) != 1 || observer.DetachBatches[0] != 5 {
+			t.Errorf("Expected a single detach batch of 5, got %v", observer.DetachBatches)
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package engine_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+// Records entities matched by change filters on each call.
+type _ChangesSys struct {
+	Added   [][]EntityID
+	Changed [][]EntityID
+	Removed [][]EntityID
+
+	SystemBase
+}
+
+func (s *_ChangesSys) Process(es *EntityStore, dt time.Duration) {
+	s.Added = append(s.Added, entityIds(MakeFinder(es).Added("value").OrderById().GetMany()))
+	s.Changed = append(s.Changed, entityIds(MakeFinder(es).Changed("value").OrderById().GetMany()))
+	s.Removed = append(s.Removed, entityIds(MakeFinder(es).Removed("value").OrderById().GetMany()))
+}
+
+func makeChangesSys() *_ChangesSys {
+	return &_ChangesSys{
+		SystemBase: *MakeSystemBase("sys_changes", 0, 0),
+	}
+}
+
+func entityIds(entities []Entity) []EntityID {
+	ids := make([]EntityID, len(entities))
+
+	for i, e := range entities {
+		ids[i] = e.Id()
+	}
+
+	return ids
+}
+
+func TestChangeDetection(t *testing.T) {
+	t.Run("Process should advance the world tick", func(t *testing.T) {
+		ecs := MakeECS()
+		ecs.SystemStore.Add(makeChangesSys())
+
+		before := ecs.EntityStore.Tick()
+		ecs.Process()
+
+		if ecs.EntityStore.Tick() != before+1 {
+			t.Errorf("Expected tick %d, got %d", before+1, ecs.EntityStore.Tick())
+		}
+
+		if ecs.SystemStore.LastRunTick("sys_changes") != before {
+			t.Errorf("Expected last run tick %d, got %d", before, ecs.SystemStore.LastRunTick("sys_changes"))
+		}
+	})
+
+	t.Run("Systems should see changes made since their last run only", func(t *testing.T) {
+		ecs := MakeECS()
+		sys := makeChangesSys()
+		ecs.SystemStore.Add(sys)
+
+		e1 := ecs.EntityStore.New(&_ValueComponent{Value: 1})
+		e2 := ecs.EntityStore.New(&_ValueComponent{Value: 2})
+
+		ecs.Process()
+
+		if len(sys.Added[0]) != 2 || len(sys.Changed[0]) != 2 {
+			t.Errorf("Expected 2 added & changed entities on the first run, got %v & %v", sys.Added[0], sys.Changed[0])
+		}
+
+		ecs.Process()
+
+		if len(sys.Added[1]) != 0 || len(sys.Changed[1]) != 0 {
+			t.Errorf("Expected no changes on the second run, got %v & %v", sys.Added[1], sys.Changed[1])
+		}
+
+		(*e2.GetMut("value")).(*_ValueComponent).Value = 3
+		ecs.Process()
+
+		if len(sys.Added[2]) != 0 {
+			t.Errorf("Expected GetMut not to mark the component as added, got %v", sys.Added[2])
+		}
+
+		if len(sys.Changed[2]) != 1 || sys.Changed[2][0] != e2.Id() {
+			t.Errorf("Expected only entity %d to be changed, got %v", e2.Id(), sys.Changed[2])
+		}
+
+		ecs.EntityStore.MarkChanged(e1.Id(), "value")
+		e2.Add(&_ValueComponent{Value: 4})
+		ecs.Process()
+
+		if len(sys.Changed[3]) != 2 || len(sys.Added[3]) != 0 {
+			t.Errorf("Expected 2 changed & 0 added entities, got %v & %v", sys.Changed[3], sys.Added[3])
+		}
+	})
+
+	t.Run("Systems should see removed components", func(t *testing.T) {
+		ecs := MakeECS()
+		sys := makeChangesSys()
+		ecs.SystemStore.Add(sys)
+
+		e1 := ecs.EntityStore.New(&_ValueComponent{}, &_TestComponent{})
+		e2 := ecs.EntityStore.New(&_ValueComponent{})
+
+		ecs.Process()
+
+		e1.Remove("value")
+		ecs.EntityStore.Remove(e2.Id())
+		ecs.Process()
+
+		if len(sys.Removed[1]) != 1 || sys.Removed[1][0] != e1.Id() {
+			t.Errorf("Expected only alive entity %d in Removed, got %v", e1.Id(), sys.Removed[1])
+		}
+
+		if len(ecs.EntityStore.RemovedSince("value", 0)) != 0 {
+			t.Errorf("Expected the removal log to be trimmed after all systems have seen it")
+		}
+	})
+
+	t.Run("Since should override the threshold tick", func(t *testing.T) {
+		es := MakeEntityStore()
+		e := es.New(&_ValueComponent{})
+
+		if len(MakeFinder(es).Changed("value").Since(es.Tick()).GetMany()) != 0 {
+			t.Errorf("Expected no entities changed after the current tick")
+		}
+
+		if MakeFinder(es).Added("value").Since(0).GetOne() != e {
+			t.Errorf("Expected the entity to be added after tick 0")
+		}
+
+		e.Remove("value")
+
+		if len(es.RemovedSince("value", 0)) != 1 {
+			t.Errorf("Expected the removal to be logged")
+		}
+	})
+
+	t.Run("Removal log should stay bounded", func(t *testing.T) {
+		es := MakeEntityStore()
+		es.SetRemovedLogLimit(100)
+
+		for i := 0; i < 1000; i++ {
+			es.Remove(es.New(&_ValueComponent{}).Id())
+		}
+
+		if n := len(es.RemovedSince("value", 0)); n != 100 {
+			t.Errorf("Expected 100 logged removals in a standalone store, got %d", n)
+		}
+
+		es.ClearRemoved()
+
+		if n := len(es.RemovedSince("value", 0)); n != 0 {
+			t.Errorf("Expected the cleared log to be empty, got %d", n)
+		}
+	})
+
+	t.Run("Skipped systems should not keep the removal log", func(t *testing.T) {
+		ecs := MakeECS()
+		ecs.SystemStore.Add(makeChangesSys())
+		ecs.SystemStore.Add(makeFixedStepSys("sys_disabled", 0))
+		ecs.SystemStore.AddInState(makeFixedStepSys("sys_menu", 0), "menu")
+		ecs.SystemStore.Disable("sys_disabled")
+
+		for i := 0; i < 50; i++ {
+			for j := 0; j < 100; j++ {
+				ecs.EntityStore.Remove(ecs.EntityStore.New(&_ValueComponent{}).Id())
+			}
+
+			ecs.Process()
+		}
+
+		if n := len(ecs.EntityStore.RemovedSince("value", 0)); n != 0 {
+			t.Errorf("Expected the removal log to be trimmed, got %d entries", n)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package engine_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+func TestManualClock(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := MakeManualClock(start)
+
+	t.Run("Now should not change by itself", func(t *testing.T) {
+		if !clock.Now().Equal(start) {
+			t.Errorf("Expected %v, got %v", start, clock.Now())
+		}
+	})
+
+	t.Run("Advance should move the clock forward", func(t *testing.T) {
+		clock.Advance(time.Second)
+
+		if !clock.Now().Equal(start.Add(time.Second)) {
+			t.Errorf("Expected %v, got %v", start.Add(time.Second), clock.Now())
+		}
+	})
+
+	t.Run("Set should change the clock time", func(t *testing.T) {
+		clock.Set(start)
+
+		if !clock.Now().Equal(start) {
+			t.Errorf("Expected %v, got %v", start, clock.Now())
+		}
+	})
+}
+
+func TestECSClock(t *testing.T) {
+	t.Run("SetClock should reset last call times", func(t *testing.T) {
+		ecs := MakeECS()
+		clock := MakeManualClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+		ecs.SystemStore.Add(&_TestSystem{})
+		ecs.SetClock(clock)
+
+		if !ecs.SystemStore.LastCallTimeMap()["sys_test"].Equal(clock.Now()) {
+			t.Errorf("Expected last call time to be %v, got %v", clock.Now(), ecs.SystemStore.LastCallTimeMap()["sys_test"])
+		}
+	})
+
+	t.Run("Systems should get elapsed clock time as dt", func(t *testing.T) {
+		ecs := MakeECS()
+		clock := MakeManualClock(time.Now())
+		sys := makeFixedStepSys("sys_clock", 0)
+
+		ecs.SetClock(clock)
+		ecs.SystemStore.Add(sys)
+
+		clock.Advance(42 * time.Millisecond)
+		ecs.Process()
+
+		if sys.Dts[0] != 42*time.Millisecond {
+			t.Errorf("Expected dt to be 42ms, got %v", sys.Dts[0])
+		}
+	})
+}
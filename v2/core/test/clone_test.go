@@ -0,0 +1,136 @@
+package engine_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+type _NestedComponent struct {
+	Tags   map[string][]int
+	Target *_ValueComponent
+	Alias  *_ValueComponent
+	Any    any
+}
+
+func (c *_NestedComponent) Type() string {
+	return "nested"
+}
+
+// Points to a field of its own pointed struct, the struct & the field share the address.
+type _InteriorPointerComponent struct {
+	A *_ValueComponent
+	B *int
+}
+
+func (c *_InteriorPointerComponent) Type() string {
+	return "interior_pointer"
+}
+
+type _CloneableComponent struct {
+	CloneCalls *int
+}
+
+func (c *_CloneableComponent) Type() string {
+	return "cloneable"
+}
+
+func (c *_CloneableComponent) Clone() Component {
+	*c.CloneCalls++
+	return &_CloneableComponent{CloneCalls: c.CloneCalls}
+}
+
+func TestCloneComponent(t *testing.T) {
+	t.Run("CloneComponent should deep copy with reflection", func(t *testing.T) {
+		target := &_ValueComponent{Value: 1}
+
+		src := &_NestedComponent{
+			Tags:   map[string][]int{"a": {1, 2}},
+			Target: target,
+			Alias:  target,
+			Any:    &_ValueComponent{Value: 2},
+		}
+
+		dst := CloneComponent(src).(*_NestedComponent)
+
+		dst.Tags["a"][0] = 10
+		dst.Target.Value = 10
+		dst.Any.(*_ValueComponent).Value = 10
+
+		if src.Tags["a"][0] != 1 || target.Value != 1 || src.Any.(*_ValueComponent).Value != 2 {
+			t.Errorf("Expected the source not to be changed")
+		}
+
+		if dst.Target != dst.Alias {
+			t.Errorf("Expected shared pointers to stay shared in the copy")
+		}
+	})
+
+	t.Run("CloneComponent should use Cloneable", func(t *testing.T) {
+		calls := 0
+		src := &_CloneableComponent{CloneCalls: &calls}
+
+		CloneComponent(src)
+
+		if calls != 1 {
+			t.Errorf("Expected Clone to be called once, got %d", calls)
+		}
+	})
+}
+
+func TestDuplicate(t *testing.T) {
+	t.Run("Duplicate should create an entity with copied components", func(t *testing.T) {
+		es := MakeEntityStore()
+		e := es.New(&_ValueComponent{Value: 5}, &_TestComponent{})
+
+		dup, err := es.Duplicate(e.Id())
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		val, _ := Get[*_ValueComponent](dup)
+		val.Value = 10
+		orig, _ := Get[*_ValueComponent](e)
+
+		if dup.Id() == e.Id() || !dup.Has("TestComponent") || orig.Value != 5 {
+			t.Errorf("Expected an independent copy of the entity")
+		}
+	})
+
+	t.Run("Duplicate should call component hooks", func(t *testing.T) {
+		es := MakeEntityStore()
+		c := &_ComponentWithHooks{}
+		e := es.New(c)
+		c.OnAttachIsCalled = false
+
+		dup, _ := es.Duplicate(e.Id())
+		hooks := (*dup.GetOne("component_with_hooks")).(*_ComponentWithHooks)
+
+		if !hooks.OnAttachIsCalled || c.OnAttachIsCalled {
+			t.Errorf("Expected OnAttach to be called on the copy only")
+		}
+	})
+
+	t.Run("Duplicate should return an error for a removed entity", func(t *testing.T) {
+		es := MakeEntityStore()
+		e := es.New()
+		es.Remove(e.Id())
+
+		if _, err := es.Duplicate(e.Id()); !errors.Is(err, ErrEntityNotAlive) {
+			t.Errorf("Expected ErrEntityNotAlive, got %v", err)
+		}
+	})
+
+	t.Run("CloneComponent should copy pointers to struct fields", func(t *testing.T) {
+		src := &_InteriorPointerComponent{A: &_ValueComponent{Value: 1}}
+		src.B = &src.A.Value
+
+		dst := CloneComponent(src).(*_InteriorPointerComponent)
+
+		if dst.A == src.A || dst.B == src.B || dst.A.Value != 1 || *dst.B != 1 {
+			t.Errorf("Expected independent copies with the same values")
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package engine_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+// Removes all value entities & spawns a new one for each of them.
+type _CommandsTestSys struct {
+	SeenInProcess int
+
+	SystemBase
+}
+
+func (s *_CommandsTestSys) Process(es *EntityStore, dt time.Duration) {
+	cmd := es.Commands()
+
+	es.Query("value").Each(func(e Entity, comps []Component) {
+		cmd.Remove(e.Id())
+		cmd.New(&_TestComponent{})
+	})
+
+	s.SeenInProcess = es.Query("value").Count()
+}
+
+// Records a command targeting a removed entity on each Process.
+type _FailingCommandsSys struct {
+	Target EntityID
+
+	SystemBase
+}
+
+func (s *_FailingCommandsSys) Process(es *EntityStore, dt time.Duration) {
+	es.Commands().AddTo(s.Target, &_TestComponent{})
+}
+
+func TestCommandBuffer(t *testing.T) {
+	t.Run("Commands should be applied only on Apply", func(t *testing.T) {
+		es := MakeEntityStore()
+		e := es.New()
+		cb := MakeCommandBuffer(es)
+
+		id := cb.New(&_ValueComponent{})
+		cb.AddTo(e.Id(), &_TestComponent{})
+
+		if len(es.GetAll()) != 1 || e.Has("TestComponent") {
+			t.Errorf("Expected store to be unchanged before Apply")
+		}
+
+		cb.Apply()
+
+		if es.GetById(id) == nil {
+			t.Errorf("Expected entity %d to be created", id)
+		}
+
+		if !e.Has("TestComponent") {
+			t.Errorf("Expected TestComponent to be attached")
+		}
+
+		if cb.Len() != 0 {
+			t.Errorf("Expected buffer to be empty, got %d commands", cb.Len())
+		}
+	})
+
+	t.Run("Commands should be applied in recording order", func(t *testing.T) {
+		es := MakeEntityStore()
+		e := es.New()
+		cb := MakeCommandBuffer(es)
+
+		cb.AddTo(e.Id(), &_TestComponent{})
+		cb.RemoveFrom(e.Id(), "TestComponent")
+		cb.Apply()
+
+		if e.Has("TestComponent") {
+			t.Errorf("Expected TestComponent to be detached")
+		}
+	})
+
+	t.Run("ECS should apply store commands after system", func(t *testing.T) {
+		ecs := MakeECS()
+		sys := &_CommandsTestSys{SystemBase: *MakeSystemBase("sys_commands", 0, 0)}
+
+		ecs.SystemStore.Add(sys)
+
+		for i := 0; i < 3; i++ {
+			ecs.EntityStore.New(&_ValueComponent{Value: i})
+		}
+
+		ecs.Process()
+
+		if sys.SeenInProcess != 3 {
+			t.Errorf("Expected changes to be deferred during Process, got %d entities", sys.SeenInProcess)
+		}
+
+		if ecs.EntityStore.Query("value").Count() != 0 {
+			t.Errorf("Expected value entities to be removed")
+		}
+
+		if ecs.EntityStore.Query("TestComponent").Count() != 3 {
+			t.Errorf("Expected 3 new entities, got %d", ecs.EntityStore.Query("TestComponent").Count())
+		}
+	})
+
+	t.Run("ECS should return errors of failed commands", func(t *testing.T) {
+		for _, sync := range []SyncPoint{SyncAfterSystem, SyncAfterFrame} {
+			ecs := MakeECS()
+			ecs.SyncPoint = sync
+
+			removed := ecs.EntityStore.New()
+			ecs.EntityStore.Remove(removed.Id())
+
+			ecs.SystemStore.Add(&_FailingCommandsSys{Target: removed.Id(), SystemBase: *MakeSystemBase("sys_failing", 0, 0)})
+
+			if err := ecs.Process(); !errors.Is(err, ErrEntityNotAlive) {
+				t.Errorf("Expected ErrEntityNotAlive from Process with sync point %d, got %v", sync, err)
+			}
+
+			ecs.SetFixedStep(time.Millisecond, 1)
+
+			if err := ecs.Step(); !errors.Is(err, ErrEntityNotAlive) {
+				t.Errorf("Expected ErrEntityNotAlive from Step with sync point %d, got %v", sync, err)
+			}
+		}
+	})
+
+	t.Run("ECS should return no errors if commands succeed", func(t *testing.T) {
+		ecs := MakeECS()
+		ecs.SystemStore.Add(&_CommandsTestSys{SystemBase: *MakeSystemBase("sys_commands", 0, 0)})
+		ecs.EntityStore.New(&_ValueComponent{})
+
+		if err := errors.Join(ecs.Setup(), ecs.Process(), ecs.Cleanup()); err != nil {
+			t.Errorf("Expected no errors, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package engine_test
+
+import (
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+// Reads the store from a system while other goroutines change it.
+type _ConcurrentReaderSys struct {
+	SystemBase
+}
+
+func (s *_ConcurrentReaderSys) Process(es *EntityStore, dt time.Duration) {
+	es.Query("value").Each(func(e Entity, comps []Component) {
+		e.Has("TestComponent")
+	})
+
+	MakeFinder(es).Has("value").Where(func(e Entity) bool {
+		return e.GetOne("value") != nil
+	}).GetRows()
+
+	MakeFinder(es).Changed("value").GetMany()
+}
+
+// Calls back into the store from the attach hook.
+type _ReentrantComponent struct {
+	Attached bool
+}
+
+func (c *_ReentrantComponent) Type() string {
+	return "reentrant"
+}
+
+func (c *_ReentrantComponent) OnAttach(e Entity) {
+	c.Attached = e.Has("reentrant")
+}
+
+func (c *_ReentrantComponent) OnDetach() {}
+
+func TestConcurrentStore(t *testing.T) {
+	t.Run("Store should be usable from goroutines while ECS processes systems", func(t *testing.T) {
+		ecs := MakeECS()
+		ecs.EntityStore.SetConcurrent(true)
+		ecs.SystemStore.Add(&_ConcurrentReaderSys{SystemBase: *MakeSystemBase("sys_reader", 0, 0)})
+
+		es := &ecs.EntityStore
+		var wg sync.WaitGroup
+
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for i := 0; i < 200; i++ {
+					e := es.New(&_ValueComponent{Value: i})
+					e.Add(&_TestComponent{})
+					es.Commands().AddTo(e.Id(), &_TestComponent2{})
+
+					if i%3 == 0 {
+						es.Remove(e.Id())
+					} else {
+						es.MarkChanged(e.Id(), "value")
+						e.Remove("TestComponent")
+					}
+				}
+			}()
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 100; i++ {
+				ecs.Process()
+			}
+		}()
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 100; i++ {
+				es.GetAll()
+				es.Tick()
+				MakeFinder(es).Has("value").OrderById().GetOne()
+				es.Query("value", "TestComponent").Count()
+			}
+		}()
+
+		wg.Wait()
+		ecs.EntityStore.Commands().Apply()
+
+		expected := 4 * (200 - 67)
+
+		if len(es.GetAll()) != expected {
+			t.Errorf("Expected %d entities, got %d", expected, len(es.GetAll()))
+		}
+	})
+
+	t.Run("Relations & serialization should be safe in the concurrent mode", func(t *testing.T) {
+		es := MakeEntityStore()
+		es.SetConcurrent(true)
+		root := es.New()
+
+		var wg sync.WaitGroup
+
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for i := 0; i < 100; i++ {
+					child := es.New(&_ValueComponent{Value: i})
+					es.SetParent(child.Id(), root.Id())
+					es.Ancestors(child.Id())
+					es.Walk(root.Id(), func(e Entity, depth int) bool { return true })
+				}
+			}()
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 20; i++ {
+				es.SaveBinary(io.Discard)
+			}
+		}()
+
+		wg.Wait()
+
+		if len(es.Children(root.Id())) != 400 {
+			t.Errorf("Expected 400 children, got %d", len(es.Children(root.Id())))
+		}
+	})
+
+	t.Run("Hooks should be able to use the store in the concurrent mode", func(t *testing.T) {
+		es := MakeEntityStore()
+		es.SetConcurrent(true)
+		c := &_ReentrantComponent{}
+
+		done := make(chan struct{})
+
+		go func() {
+			es.New(c)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("Expected the hook not to deadlock")
+		}
+
+		if !c.Attached || !es.Concurrent() {
+			t.Errorf("Expected the hook to see the attached component")
+		}
+	})
+}
@@ -69,6 +69,8 @@ func TestSystemHooks(t *testing.T) {
 func TestSystemsPriority(t *testing.T) {
 	t.Run("Systems should be processed in priority order", func(t *testing.T) {
 		ecs := MakeECS()
+		clock := MakeManualClock(time.Now())
+		ecs.SetClock(clock)
 
 		var prevCallIndex int8 = -1
 		sysA := make_TEST_CORE_SYS_A(&prevCallIndex)
@@ -77,7 +79,7 @@ func TestSystemsPriority(t *testing.T) {
 		ecs.SystemStore.Add(sysA)
 		ecs.SystemStore.Add(sysB)
 
-		time.Sleep(time.Duration(sysB.Frequency()) * time.Millisecond)
+		clock.Advance(time.Duration(sysB.Frequency()) * time.Millisecond)
 
 		ecs.Process()
 
@@ -94,6 +96,8 @@ func TestSystemsPriority(t *testing.T) {
 func TestSystemsFrequency(t *testing.T) {
 	t.Run("Systems should be processed after frequency time elapsed", func(t *testing.T) {
 		ecs := MakeECS()
+		clock := MakeManualClock(time.Now())
+		ecs.SetClock(clock)
 
 		var prevCallIndex int8 = -1
 		sysA := make_TEST_CORE_SYS_A(&prevCallIndex)
@@ -108,7 +112,7 @@ func TestSystemsFrequency(t *testing.T) {
 			t.Errorf("Expected system B to not be called, got %d", sysB.CalledTimes)
 		}
 
-		time.Sleep(time.Millisecond * time.Duration(sysB.Frequency()))
+		clock.Advance(time.Millisecond * time.Duration(sysB.Frequency()))
 
 		ecs.Process()
 
@@ -1,6 +1,7 @@
 package engine_test
 
 import (
+	"errors"
 	"testing"
 
 	. "github.com/kostayne/ecs/v2/core"
@@ -138,3 +139,68 @@ func TestEntityStoreGetById(t *testing.T) {
 		}
 	})
 }
+
+func TestEntityStoreGenerations(t *testing.T) {
+	t.Run("Removed entity should not be alive", func(t *testing.T) {
+		es := MakeEntityStore()
+		e := es.New()
+
+		if !es.Alive(e.Id()) {
+			t.Errorf("Expected entity to be alive")
+		}
+
+		es.Remove(e.Id())
+
+		if es.Alive(e.Id()) {
+			t.Errorf("Expected entity not to be alive")
+		}
+	})
+
+	t.Run("Freed slot should be reused with a new generation", func(t *testing.T) {
+		es := MakeEntityStore()
+		old := es.New()
+
+		es.Remove(old.Id())
+		e := es.New()
+
+		if e.Id().Index() != old.Id().Index() {
+			t.Errorf("Expected slot %d to be reused, got %d", old.Id().Index(), e.Id().Index())
+		}
+
+		if e.Id().Generation() != old.Id().Generation()+1 {
+			t.Errorf("Expected generation %d, got %d", old.Id().Generation()+1, e.Id().Generation())
+		}
+
+		if es.Alive(old.Id()) {
+			t.Errorf("Expected stale ID not to be alive")
+		}
+	})
+
+	t.Run("Operations on stale handles should fail", func(t *testing.T) {
+		es := MakeEntityStore()
+		old := es.New()
+
+		es.Remove(old.Id())
+		e := es.New(&_TestComponent{})
+
+		if err := old.Add(&_TestComponent2{}); !errors.Is(err, ErrEntityNotAlive) {
+			t.Errorf("Expected ErrEntityNotAlive, got %v", err)
+		}
+
+		if err := es.RemoveFrom(old.Id(), "TestComponent"); !errors.Is(err, ErrEntityNotAlive) {
+			t.Errorf("Expected ErrEntityNotAlive, got %v", err)
+		}
+
+		if err := es.Remove(old.Id()); !errors.Is(err, ErrEntityNotAlive) {
+			t.Errorf("Expected ErrEntityNotAlive, got %v", err)
+		}
+
+		if old.Has("TestComponent") || len(old.GetAll()) != 0 {
+			t.Errorf("Expected stale handle to see no components")
+		}
+
+		if !e.Has("TestComponent") || e.Has("TestComponent2") {
+			t.Errorf("Expected the new entity to be untouched by the stale handle")
+		}
+	})
+}
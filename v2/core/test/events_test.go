@@ -0,0 +1,123 @@
+package engine_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+type _CollisionEvent struct {
+	A, B EntityID
+}
+
+type _DamageEvent struct {
+	Amount int
+}
+
+// Posts a damage event every frame & reads events of the previous frame.
+type _DamageSys struct {
+	bus  *EventBus
+	Read []int
+
+	SystemBase
+}
+
+func (s *_DamageSys) Process(es *EntityStore, dt time.Duration) {
+	for _, e := range ReadEvents[_DamageEvent](s.bus) {
+		s.Read = append(s.Read, e.Amount)
+	}
+
+	PostEvent(s.bus, _DamageEvent{Amount: len(s.Read) + 1})
+}
+
+func TestEventBus(t *testing.T) {
+	t.Run("Emitted events should be delivered immediately", func(t *testing.T) {
+		bus := MakeEventBus()
+		received := make([]_CollisionEvent, 0)
+
+		SubscribeEvent(bus, func(e _CollisionEvent) {
+			received = append(received, e)
+		})
+
+		EmitEvent(bus, _CollisionEvent{A: 1, B: 2})
+
+		if len(received) != 1 || received[0].B != 2 {
+			t.Errorf("Expected 1 collision event, got %v", received)
+		}
+	})
+
+	t.Run("Events should be delivered only to subscribers of the same type", func(t *testing.T) {
+		bus := MakeEventBus()
+		called := false
+
+		SubscribeEvent(bus, func(e _DamageEvent) { called = true })
+		EmitEvent(bus, _CollisionEvent{})
+
+		if called {
+			t.Errorf("Expected damage handler not to be called")
+		}
+	})
+
+	t.Run("Posted events should be buffered until Flush", func(t *testing.T) {
+		bus := MakeEventBus()
+		received := make([]int, 0)
+
+		SubscribeEvent(bus, func(e _DamageEvent) {
+			received = append(received, e.Amount)
+		})
+
+		PostEvent(bus, _DamageEvent{Amount: 1})
+		PostEvent(bus, _DamageEvent{Amount: 2})
+
+		if len(received) != 0 || len(ReadEvents[_DamageEvent](bus)) != 0 {
+			t.Errorf("Expected events not to be delivered before Flush")
+		}
+
+		bus.Flush()
+
+		if !slices.Equal(received, []int{1, 2}) {
+			t.Errorf("Expected [1 2], got %v", received)
+		}
+
+		if len(ReadEvents[_DamageEvent](bus)) != 2 {
+			t.Errorf("Expected 2 readable events, got %d", len(ReadEvents[_DamageEvent](bus)))
+		}
+
+		bus.Flush()
+
+		if len(ReadEvents[_DamageEvent](bus)) != 0 {
+			t.Errorf("Expected events to be cleared on the next frame")
+		}
+	})
+
+	t.Run("Unsubscribed handler should not be called", func(t *testing.T) {
+		bus := MakeEventBus()
+		called := false
+
+		sub := SubscribeEvent(bus, func(e _DamageEvent) { called = true })
+		sub.Unsubscribe()
+
+		EmitEvent(bus, _DamageEvent{})
+
+		if called {
+			t.Errorf("Expected handler not to be called")
+		}
+	})
+
+	t.Run("ECS.Process should flush events every frame", func(t *testing.T) {
+		ecs := MakeECS()
+		sys := &_DamageSys{bus: ecs.Events, SystemBase: *MakeSystemBase("sys_damage", 0, 0)}
+
+		ecs.SystemStore.Add(sys)
+
+		for i := 0; i < 3; i++ {
+			ecs.Process()
+		}
+
+		if !slices.Equal(sys.Read, []int{1, 2}) {
+			t.Errorf("Expected events of previous frames [1 2], got %v", sys.Read)
+		}
+	})
+}
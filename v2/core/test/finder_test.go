@@ -104,3 +104,75 @@ func TestFinderHas(t *testing.T) {
 		}
 	})
 }
+
+func TestFinderWithout(t *testing.T) {
+	es := MakeEntityStore()
+
+	es.New(&_TestComponent{})
+	es.New(&_TestComponent{}, &_TestComponent2{})
+	es.New(&_TestComponent2{})
+
+	tg := len(MakeFinder(es).Has("TestComponent").Without("TestComponent2").GetMany())
+
+	if tg != 1 {
+		t.Errorf("Expected 1 entity, got %d", tg)
+	}
+}
+
+func TestFinderAnyOf(t *testing.T) {
+	es := MakeEntityStore()
+
+	es.New(&_TestComponent{})
+	es.New(&_TestComponent2{})
+	es.New(&_ValueComponent{})
+	es.New()
+
+	tg := len(MakeFinder(es).AnyOf("TestComponent", "TestComponent2").GetMany())
+
+	if tg != 2 {
+		t.Errorf("Expected 2 entities, got %d", tg)
+	}
+}
+
+func TestFinderOptional(t *testing.T) {
+	es := MakeEntityStore()
+
+	es.New(&_ValueComponent{Value: 1}, &_TestComponent{})
+	es.New(&_ValueComponent{Value: 2})
+
+	rows := MakeFinder(es).Has("value").Optional("TestComponent").OrderById().GetRows()
+
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(rows))
+	}
+
+	if rows[0].Components[0].(*_ValueComponent).Value != 1 || rows[0].Components[1] == nil {
+		t.Errorf("Expected the first row to have value 1 & TestComponent")
+	}
+
+	if rows[1].Components[0].(*_ValueComponent).Value != 2 || rows[1].Components[1] != nil {
+		t.Errorf("Expected the second row to have value 2 & no TestComponent")
+	}
+}
+
+func TestFinderOrderById(t *testing.T) {
+	es := MakeEntityStore()
+
+	// entities end up in different archetypes, so the storage order differs from the ID order
+	e1 := es.New(&_TestComponent{})
+	e2 := es.New()
+	e3 := es.New(&_TestComponent{}, &_TestComponent2{})
+	e1.Remove("TestComponent")
+
+	found := MakeFinder(es).OrderById().GetMany()
+
+	for i, e := range []Entity{e1, e2, e3} {
+		if found[i].Id() != e.Id() {
+			t.Errorf("Expected entity %d at %d, got %d", e.Id(), i, found[i].Id())
+		}
+	}
+
+	if MakeFinder(es).OrderById().GetOne().Id() != e1.Id() {
+		t.Errorf("Expected GetOne to return entity %d", e1.Id())
+	}
+}
@@ -0,0 +1,147 @@
+package engine_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+type _FixedStepSys struct {
+	Dts         []time.Duration
+	Alphas      []float64
+	CalledTimes int
+
+	SystemBase
+}
+
+func (s *_FixedStepSys) Process(es *EntityStore, dt time.Duration) {
+	s.Dts = append(s.Dts, dt)
+	s.CalledTimes++
+}
+
+func (s *_FixedStepSys) Interpolate(es *EntityStore, alpha float64) {
+	s.Alphas = append(s.Alphas, alpha)
+}
+
+func makeFixedStepSys(sysType string, frequency uint) *_FixedStepSys {
+	return &_FixedStepSys{
+		SystemBase: *MakeSystemBase(sysType, frequency, 0),
+	}
+}
+
+func TestFixedStep(t *testing.T) {
+	t.Run("Step should pass constant dt", func(t *testing.T) {
+		ecs := MakeECS()
+		sys := makeFixedStepSys("sys_fixed", 0)
+
+		ecs.SystemStore.Add(sys)
+		ecs.SetFixedStep(10*time.Millisecond, 5)
+
+		for i := 0; i < 3; i++ {
+			ecs.Step()
+		}
+
+		if sys.CalledTimes != 3 {
+			t.Errorf("Expected 3 calls, got %d", sys.CalledTimes)
+		}
+
+		for _, dt := range sys.Dts {
+			if dt != 10*time.Millisecond {
+				t.Errorf("Expected dt to be 10ms, got %v", dt)
+			}
+		}
+	})
+
+	t.Run("Frequency should be measured in simulated time", func(t *testing.T) {
+		ecs := MakeECS()
+		sys := makeFixedStepSys("sys_fixed", 15)
+
+		ecs.SystemStore.Add(sys)
+		ecs.SetFixedStep(10*time.Millisecond, 5)
+
+		for i := 0; i < 4; i++ {
+			ecs.Step()
+		}
+
+		if sys.CalledTimes != 2 {
+			t.Errorf("Expected 2 calls, got %d", sys.CalledTimes)
+		}
+
+		if sys.Dts[0] != 20*time.Millisecond {
+			t.Errorf("Expected the first dt to be 20ms, got %v", sys.Dts[0])
+		}
+	})
+
+	t.Run("Process should not step before the step time is accumulated", func(t *testing.T) {
+		ecs := MakeECS()
+		sys := makeFixedStepSys("sys_fixed", 0)
+
+		ecs.SystemStore.Add(sys)
+		ecs.SetFixedStep(time.Hour, 5)
+
+		ecs.Process()
+
+		if sys.CalledTimes != 0 {
+			t.Errorf("Expected no calls, got %d", sys.CalledTimes)
+		}
+
+		if len(sys.Alphas) != 1 {
+			t.Errorf("Expected Interpolate to be called once, got %d", len(sys.Alphas))
+		}
+
+		if ecs.Alpha() < 0 || ecs.Alpha() >= 1 {
+			t.Errorf("Expected alpha in [0, 1) range, got %v", ecs.Alpha())
+		}
+	})
+
+	t.Run("Process should run steps for accumulated time & expose alpha", func(t *testing.T) {
+		ecs := MakeECS()
+		clock := MakeManualClock(time.Now())
+		sys := makeFixedStepSys("sys_fixed", 0)
+
+		ecs.SetClock(clock)
+		ecs.SystemStore.Add(sys)
+		ecs.SetFixedStep(10*time.Millisecond, 5)
+
+		ecs.Process()
+		clock.Advance(25 * time.Millisecond)
+		ecs.Process()
+
+		if sys.CalledTimes != 2 {
+			t.Errorf("Expected 2 steps, got %d", sys.CalledTimes)
+		}
+
+		if ecs.Alpha() != 0.5 {
+			t.Errorf("Expected alpha to be 0.5, got %v", ecs.Alpha())
+		}
+	})
+
+	t.Run("Process should not run more than max steps", func(t *testing.T) {
+		ecs := MakeECS()
+		clock := MakeManualClock(time.Now())
+		sys := makeFixedStepSys("sys_fixed", 0)
+
+		ecs.SetClock(clock)
+		ecs.SystemStore.Add(sys)
+		ecs.SetFixedStep(10*time.Millisecond, 3)
+
+		ecs.Process()
+		clock.Advance(time.Second)
+		ecs.Process()
+
+		if sys.CalledTimes != 3 {
+			t.Errorf("Expected 3 steps, got %d", sys.CalledTimes)
+		}
+	})
+
+	t.Run("Step should panic if fixed step mode is disabled", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("Expected panic, got nil")
+			}
+		}()
+
+		MakeECS().Step()
+	})
+}
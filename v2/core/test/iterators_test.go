@@ -0,0 +1,157 @@
+package engine_test
+
+import (
+	"testing"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+func TestEntityStoreEach(t *testing.T) {
+	t.Run("Each should iterate all entities after removals", func(t *testing.T) {
+		es := MakeEntityStore()
+		ents := make([]Entity, 0)
+
+		for i := 0; i < 5; i++ {
+			ents = append(ents, es.New(&_ValueComponent{Value: i}))
+		}
+
+		es.Remove(ents[0].Id())
+		es.Remove(ents[1].Id())
+		es.New()
+
+		count := 0
+
+		for range es.Each() {
+			count++
+		}
+
+		if count != 4 || len(es.GetAll()) != 4 {
+			t.Errorf("Expected 4 entities, got %d & %d", count, len(es.GetAll()))
+		}
+	})
+
+	t.Run("Each should support early break", func(t *testing.T) {
+		es := MakeEntityStore()
+
+		for i := 0; i < 5; i++ {
+			es.New()
+		}
+
+		count := 0
+
+		for range es.Each() {
+			count++
+
+			if count == 2 {
+				break
+			}
+		}
+
+		if count != 2 {
+			t.Errorf("Expected 2 iterations, got %d", count)
+		}
+	})
+}
+
+func TestFinderAll(t *testing.T) {
+	t.Run("All should yield matched entities lazily", func(t *testing.T) {
+		es := MakeEntityStore()
+
+		for i := 0; i < 5; i++ {
+			es.New(&_ValueComponent{Value: i})
+			es.New(&_TestComponent{})
+		}
+
+		checked := 0
+
+		f := MakeFinder(es).Has("value").Where(func(e Entity) bool {
+			checked++
+			return true
+		})
+
+		for range f.All() {
+			break
+		}
+
+		if checked != 1 {
+			t.Errorf("Expected the predicate to be called once, got %d", checked)
+		}
+	})
+
+	t.Run("All should keep OrderById", func(t *testing.T) {
+		es := MakeEntityStore()
+		e1 := es.New(&_TestComponent{})
+		e2 := es.New(&_ValueComponent{}, &_TestComponent{})
+		es.New(&_TestComponent{})
+
+		ids := make([]EntityID, 0)
+
+		for e := range MakeFinder(es).Has("TestComponent").OrderById().All() {
+			ids = append(ids, e.Id())
+		}
+
+		if len(ids) != 3 || ids[0] != e1.Id() || ids[1] != e2.Id() {
+			t.Errorf("Expected entities ordered by ID, got %v", ids)
+		}
+	})
+}
+
+func TestTypedQueries(t *testing.T) {
+	t.Run("Query2 should yield typed component pairs", func(t *testing.T) {
+		es := MakeEntityStore()
+		es.New(&_ValueComponent{Value: 1}, &_InventoryComponent{Items: []string{"a"}})
+		es.New(&_ValueComponent{Value: 2})
+
+		count := 0
+
+		for e, p := range MakeQuery2[*_ValueComponent, *_InventoryComponent](es).All() {
+			count++
+
+			if p.A.Value != 1 || p.B.Items[0] != "a" || !e.Has("value", "inventory") {
+				t.Errorf("Expected matched components, got %v & %v", p.A, p.B)
+			}
+		}
+
+		if count != 1 {
+			t.Errorf("Expected 1 entity, got %d", count)
+		}
+	})
+
+	t.Run("Query1 should yield typed components", func(t *testing.T) {
+		es := MakeEntityStore()
+		es.New(&_ValueComponent{Value: 1})
+		es.New(&_ValueComponent{Value: 2})
+
+		sum := 0
+
+		for _, v := range MakeQuery1[*_ValueComponent](es).All() {
+			sum += v.Value
+		}
+
+		if sum != 3 {
+			t.Errorf("Expected sum 3, got %d", sum)
+		}
+	})
+
+	t.Run("Typed iteration should not allocate per entity", func(t *testing.T) {
+		allocs := func(n int) float64 {
+			es := MakeEntityStore()
+
+			for i := 0; i < n; i++ {
+				es.New(&_ValueComponent{Value: i}, &_InventoryComponent{})
+			}
+
+			q := MakeQuery2[*_ValueComponent, *_InventoryComponent](es)
+
+			return testing.AllocsPerRun(10, func() {
+				for _, p := range q.All() {
+					p.A.Value++
+				}
+			})
+		}
+
+		if small, large := allocs(10), allocs(1000); small != large {
+			t.Errorf("Expected allocations not to depend on entities count, got %v & %v", small, large)
+		}
+	})
+}
@@ -105,4 +105,36 @@ func TestObservers(t *testing.T) {
 		newEnt := ecs.EntityStore.New(comp)
 		ecs.EntityStore.Remove(newEnt.Id())
 	})
+
+	t.Run("Refresh should apply observed types changed after adding", func(t *testing.T) {
+		es := MakeEntityStore()
+		observer := &_RecordingObserver{}
+		observer.SetObservedTypes("value")
+		handle := es.AddObserver(observer)
+
+		observer.SetObservedTypes("TestComponent")
+		handle.Refresh()
+		e := es.New(&_ValueComponent{}, &_TestComponent{})
+
+		if observer.Attached != 1 {
+			t.Errorf("Expected only TestComponent attachment, got %d", observer.Attached)
+		}
+
+		tagObserver := &_RecordingTagObserver{}
+		tagHandle := es.AddObserver(tagObserver)
+		tagObserver.tags = []string{"enemy"}
+		e.Tag("enemy")
+
+		if tagObserver.Attached != 0 {
+			t.Errorf("Expected no tag attachment before Refresh, got %d", tagObserver.Attached)
+		}
+
+		tagHandle.Refresh()
+		e.Untag("enemy")
+		e.Tag("enemy")
+
+		if tagObserver.Attached != 1 {
+			t.Errorf("Expected the tag attachment, got %d", tagObserver.Attached)
+		}
+	})
 }
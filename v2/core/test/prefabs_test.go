@@ -0,0 +1,133 @@
+package engine_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+type _InventoryComponent struct {
+	Items []string
+}
+
+func (c *_InventoryComponent) Type() string {
+	return "inventory"
+}
+
+func TestPrefabs(t *testing.T) {
+	t.Run("SpawnPrefab should deep clone prefab components", func(t *testing.T) {
+		es := MakeEntityStore()
+
+		es.RegisterPrefab(Prefab{
+			Name:       "chest",
+			Components: []Component{&_InventoryComponent{Items: []string{"gold"}}},
+		})
+
+		a, _ := es.SpawnPrefab("chest")
+		b, _ := es.SpawnPrefab("chest")
+
+		invA, _ := Get[*_InventoryComponent](a)
+		invB, _ := Get[*_InventoryComponent](b)
+		invA.Items[0] = "sword"
+
+		if invB.Items[0] != "gold" {
+			t.Errorf("Expected prefab instances not to share state, got %v", invB.Items)
+		}
+
+		prefab, _ := es.GetPrefab("chest")
+
+		if prefab.Components[0].(*_InventoryComponent).Items[0] != "gold" {
+			t.Errorf("Expected the prefab not to be changed")
+		}
+	})
+
+	t.Run("Overrides should replace prefab components", func(t *testing.T) {
+		es := MakeEntityStore()
+
+		es.RegisterPrefab(Prefab{
+			Name:       "enemy",
+			Components: []Component{&_ValueComponent{Value: 10}, &_TestComponent{}},
+		})
+
+		e, _ := es.SpawnPrefab("enemy", &_ValueComponent{Value: 20}, &_TestComponent2{})
+		val, _ := Get[*_ValueComponent](e)
+
+		if val.Value != 20 {
+			t.Errorf("Expected the overridden value to be 20, got %d", val.Value)
+		}
+
+		if !e.Has("value", "TestComponent", "TestComponent2") {
+			t.Errorf("Expected prefab & override components to be attached")
+		}
+	})
+
+	t.Run("Child prefabs should be spawned as children", func(t *testing.T) {
+		es := MakeEntityStore()
+
+		es.RegisterPrefab(Prefab{Name: "weapon", Components: []Component{&_TestComponent{}}})
+		es.RegisterPrefab(Prefab{Name: "player", Components: []Component{&_ValueComponent{}}, Children: []string{"weapon", "weapon"}})
+
+		player, err := es.SpawnPrefab("player")
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		weapons := MakeFinder(es).ChildOf(player.Id()).Has("TestComponent").GetMany()
+
+		if len(weapons) != 2 {
+			t.Errorf("Expected 2 weapon children, got %d", len(weapons))
+		}
+	})
+
+	t.Run("SpawnPrefab should return errors without spawning entities", func(t *testing.T) {
+		es := MakeEntityStore()
+
+		es.RegisterPrefab(Prefab{Name: "a", Children: []string{"b"}})
+		es.RegisterPrefab(Prefab{Name: "b", Children: []string{"a"}})
+		es.RegisterPrefab(Prefab{Name: "c", Children: []string{"missing"}})
+
+		if _, err := es.SpawnPrefab("a"); !errors.Is(err, ErrPrefabCycle) {
+			t.Errorf("Expected ErrPrefabCycle, got %v", err)
+		}
+
+		if _, err := es.SpawnPrefab("c"); !errors.Is(err, ErrUnknownPrefab) {
+			t.Errorf("Expected ErrUnknownPrefab, got %v", err)
+		}
+
+		if len(es.GetAll()) != 0 {
+			t.Errorf("Expected no entities to be spawned")
+		}
+	})
+
+	t.Run("LoadPrefabsJSON should create components with the registry", func(t *testing.T) {
+		RegisterComponentType[*_ValueComponent]()
+		RegisterComponentType[*_InventoryComponent]()
+		es := MakeEntityStore()
+
+		err := es.LoadPrefabsJSON(strings.NewReader(`{
+			"coin": {"components": {"value": {"Value": 5}}},
+			"bag": {"components": {"inventory": {"Items": ["apple"]}}, "children": ["coin"]}
+		}`))
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		bag, _ := es.SpawnPrefab("bag")
+		inv, _ := Get[*_InventoryComponent](bag)
+		coin := es.GetById(es.Children(bag.Id())[0])[0].(*_ValueComponent)
+
+		if inv.Items[0] != "apple" || coin.Value != 5 {
+			t.Errorf("Expected loaded prefab components, got %v & %d", inv.Items, coin.Value)
+		}
+
+		err = es.LoadPrefabsJSON(strings.NewReader(`{"bad": {"components": {"unknown": {}}}}`))
+
+		if !errors.Is(err, ErrUnknownComponent) {
+			t.Errorf("Expected ErrUnknownComponent, got %v", err)
+		}
+	})
+}
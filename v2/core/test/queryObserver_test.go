@@ -0,0 +1,177 @@
+package engine_test
+
+import (
+	"testing"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+func TestQueryObserver(t *testing.T) {
+	t.Run("OnEnter should be called when an entity gets all observed types", func(t *testing.T) {
+		es := MakeEntityStore()
+		entered := make([]EntityID, 0)
+
+		es.Observe("value", "TestComponent").OnEnter(func(e Entity) {
+			entered = append(entered, e.Id())
+		})
+
+		e := es.New(&_ValueComponent{})
+		es.New(&_ValueComponent{}, &_TestComponent{})
+
+		if len(entered) != 1 {
+			t.Errorf("Expected only the full match to enter, got %d", len(entered))
+		}
+
+		e.Add(&_TestComponent{})
+		e.Add(&_TestComponent{})
+
+		if len(entered) != 2 || entered[1] != e.Id() {
+			t.Errorf("Expected the entity to enter once, got %v", entered)
+		}
+	})
+
+	t.Run("OnSet should be called on replacement only", func(t *testing.T) {
+		es := MakeEntityStore()
+		legacy := &_RecordingObserver{}
+		legacy.SetObservedTypes("value")
+		es.AddObserver(legacy)
+
+		values := make([]int, 0)
+
+		es.Observe("value").OnSet(func(e Entity, c Component) {
+			values = append(values, c.(*_ValueComponent).Value)
+		})
+
+		e := es.New(&_ValueComponent{Value: 1})
+		e.Add(&_ValueComponent{Value: 2}, &_TestComponent{})
+
+		if len(values) != 1 || values[0] != 2 {
+			t.Errorf("Expected a single set with the new component, got %v", values)
+		}
+
+		if legacy.Attached != 1 {
+			t.Errorf("Expected replacement not to be reported as attachment, got %d", legacy.Attached)
+		}
+	})
+
+	t.Run("OnLeave should be called before an observed type is detached", func(t *testing.T) {
+		es := MakeEntityStore()
+		left := 0
+
+		es.Observe("value", "TestComponent").OnLeave(func(e Entity) {
+			if e.Has("value", "TestComponent") {
+				left++
+			}
+		})
+
+		e := es.New(&_ValueComponent{}, &_TestComponent{})
+		e.Remove("value")
+		e.Remove("TestComponent")
+
+		removed := es.New(&_ValueComponent{}, &_TestComponent{})
+		es.Remove(removed.Id())
+
+		if left != 2 {
+			t.Errorf("Expected 2 leave calls, got %d", left)
+		}
+	})
+
+	t.Run("OnDestroy should be called before components are detached", func(t *testing.T) {
+		es := MakeEntityStore()
+		destroyed := 0
+		left := 0
+
+		es.Observe("value").OnDestroy(func(e Entity) {
+			if e.Has("value") {
+				destroyed++
+			}
+		})
+
+		es.Observe().OnLeave(func(e Entity) {
+			left++
+		})
+
+		e := es.New(&_ValueComponent{})
+		es.New()
+		e.Remove("value")
+		es.Remove(e.Id())
+
+		if destroyed != 0 || left != 1 {
+			t.Errorf("Expected no destroy & a single leave call, got %d & %d", destroyed, left)
+		}
+
+		es.Remove(es.New(&_ValueComponent{}).Id())
+
+		if destroyed != 1 {
+			t.Errorf("Expected a destroy call, got %d", destroyed)
+		}
+	})
+
+	t.Run("Batch operations should notify query observers", func(t *testing.T) {
+		es := MakeEntityStore()
+		entered := 0
+		left := 0
+
+		es.Observe("value").OnEnter(func(e Entity) { entered++ })
+		es.Observe("value").OnLeave(func(e Entity) { left++ })
+
+		es.NewBatch(10, func(i int) []Component {
+			return []Component{&_ValueComponent{Value: i}}
+		})
+
+		es.RemoveAll("value")
+
+		if entered != 10 || left != 10 {
+			t.Errorf("Expected 10 enter & leave calls, got %d & %d", entered, left)
+		}
+	})
+
+	t.Run("Repeated component types should attach the last component once", func(t *testing.T) {
+		es := MakeEntityStore()
+		observer := &_RecordingObserver{}
+		observer.SetObservedTypes("component_with_hooks")
+		es.AddObserver(observer)
+
+		first, last := &_ComponentWithHooks{}, &_ComponentWithHooks{}
+		e := es.New(first, last)
+
+		replaced, added := &_ComponentWithHooks{}, &_ComponentWithHooks{}
+		other := es.New()
+		other.Add(replaced, added)
+
+		if observer.Attached != 2 {
+			t.Errorf("Expected a single attach per entity, got %d", observer.Attached)
+		}
+
+		if first.OnAttachIsCalled || replaced.OnAttachIsCalled || !last.OnAttachIsCalled || !added.OnAttachIsCalled {
+			t.Errorf("Expected only the last components to be attached")
+		}
+
+		if *e.GetOne("component_with_hooks") != last || *other.GetOne("component_with_hooks") != added {
+			t.Errorf("Expected the last components to be stored")
+		}
+	})
+}
+
+func TestObserverHandles(t *testing.T) {
+	t.Run("Unsubscribe should stop notifications", func(t *testing.T) {
+		es := MakeEntityStore()
+		entered := 0
+
+		handle := es.Observe("value").OnEnter(func(e Entity) { entered++ })
+
+		observer := &_RecordingObserver{}
+		observer.SetObservedTypes("value")
+		observerHandle := es.AddObserver(observer)
+
+		es.New(&_ValueComponent{})
+		handle.Unsubscribe()
+		observerHandle.Unsubscribe()
+		handle.Unsubscribe()
+		es.New(&_ValueComponent{})
+
+		if entered != 1 || observer.Attached != 1 {
+			t.Errorf("Expected a single notification, got %d & %d", entered, observer.Attached)
+		}
+	})
+}
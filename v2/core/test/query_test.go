@@ -0,0 +1,83 @@
+package engine_test
+
+import (
+	"testing"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+func TestQuery(t *testing.T) {
+	t.Run("Same types should return the same query", func(t *testing.T) {
+		es := MakeEntityStore()
+
+		if es.Query("value") != es.Query("value") {
+			t.Errorf("Expected the same query instance")
+		}
+	})
+
+	t.Run("Query should match entities created before & after registration", func(t *testing.T) {
+		es := MakeEntityStore()
+		es.New(&_ValueComponent{}, &_TestComponent{})
+
+		q := es.Query("value", "TestComponent")
+
+		es.New(&_ValueComponent{}, &_TestComponent{}, &_TestComponent2{})
+		es.New(&_ValueComponent{})
+
+		if q.Count() != 2 {
+			t.Errorf("Expected 2 entities, got %d", q.Count())
+		}
+	})
+
+	t.Run("Query should follow AddTo, RemoveFrom & Remove", func(t *testing.T) {
+		es := MakeEntityStore()
+		q := es.Query("value")
+
+		e1 := es.New()
+		e2 := es.New(&_ValueComponent{})
+
+		es.AddTo(e1.Id(), &_ValueComponent{})
+
+		if q.Count() != 2 {
+			t.Errorf("Expected 2 entities after AddTo, got %d", q.Count())
+		}
+
+		es.RemoveFrom(e1.Id(), "value")
+		es.Remove(e2.Id())
+
+		if q.Count() != 0 {
+			t.Errorf("Expected 0 entities after removal, got %d", q.Count())
+		}
+	})
+
+	t.Run("Each should pass components in query order", func(t *testing.T) {
+		es := MakeEntityStore()
+		es.New(&_TestComponent{}, &_ValueComponent{Value: 3})
+
+		es.Query("value", "TestComponent").Each(func(e Entity, comps []Component) {
+			if comps[0].Type() != "value" || comps[1].Type() != "TestComponent" {
+				t.Errorf("Expected value & TestComponent, got %s & %s", comps[0].Type(), comps[1].Type())
+			}
+		})
+	})
+
+	t.Run("Each should not allocate", func(t *testing.T) {
+		es := MakeEntityStore()
+
+		for i := 0; i < 100; i++ {
+			es.New(&_ValueComponent{Value: i})
+		}
+
+		q := es.Query("value")
+
+		allocs := testing.AllocsPerRun(10, func() {
+			q.Each(func(e Entity, comps []Component) {
+				comps[0].(*_ValueComponent).Value++
+			})
+		})
+
+		if allocs != 0 {
+			t.Errorf("Expected 0 allocations, got %v", allocs)
+		}
+	})
+}
@@ -0,0 +1,207 @@
+package engine_test
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+func TestRelations(t *testing.T) {
+	t.Run("SetParent should link parent & children", func(t *testing.T) {
+		es := MakeEntityStore()
+		parent := es.New()
+		child1 := es.New()
+		child2 := es.New()
+
+		es.SetParent(child1.Id(), parent.Id())
+		es.SetParent(child2.Id(), parent.Id())
+
+		if p, ok := es.Parent(child1.Id()); !ok || p != parent.Id() {
+			t.Errorf("Expected parent %d, got %d (ok=%v)", parent.Id(), p, ok)
+		}
+
+		children := es.Children(parent.Id())
+
+		if !slices.Equal(children, []EntityID{child1.Id(), child2.Id()}) {
+			t.Errorf("Expected children %v, got %v", []EntityID{child1.Id(), child2.Id()}, children)
+		}
+	})
+
+	t.Run("SetParent should replace the previous parent", func(t *testing.T) {
+		es := MakeEntityStore()
+		p1 := es.New()
+		p2 := es.New()
+		child := es.New()
+
+		es.SetParent(child.Id(), p1.Id())
+		es.SetParent(child.Id(), p2.Id())
+
+		if len(es.Children(p1.Id())) != 0 {
+			t.Errorf("Expected the old parent to have no children")
+		}
+
+		if p, _ := es.Parent(child.Id()); p != p2.Id() {
+			t.Errorf("Expected parent %d, got %d", p2.Id(), p)
+		}
+	})
+
+	t.Run("SetParent should reject cycles", func(t *testing.T) {
+		es := MakeEntityStore()
+		root := es.New()
+		child := es.New()
+
+		es.SetParent(child.Id(), root.Id())
+
+		if err := es.SetParent(root.Id(), child.Id()); !errors.Is(err, ErrRelationCycle) {
+			t.Errorf("Expected ErrRelationCycle, got %v", err)
+		}
+
+		if err := es.SetParent(root.Id(), root.Id()); !errors.Is(err, ErrRelationCycle) {
+			t.Errorf("Expected ErrRelationCycle, got %v", err)
+		}
+	})
+
+	t.Run("Ancestors & Walk should traverse the hierarchy", func(t *testing.T) {
+		es := MakeEntityStore()
+		root := es.New()
+		child := es.New()
+		grandChild := es.New()
+
+		es.SetParent(child.Id(), root.Id())
+		es.SetParent(grandChild.Id(), child.Id())
+
+		if !slices.Equal(es.Ancestors(grandChild.Id()), []EntityID{child.Id(), root.Id()}) {
+			t.Errorf("Expected ancestors %v, got %v", []EntityID{child.Id(), root.Id()}, es.Ancestors(grandChild.Id()))
+		}
+
+		depths := make([]int, 0)
+
+		es.Walk(root.Id(), func(e Entity, depth int) bool {
+			depths = append(depths, depth)
+			return true
+		})
+
+		if !slices.Equal(depths, []int{0, 1, 2}) {
+			t.Errorf("Expected depths [0 1 2], got %v", depths)
+		}
+
+		visited := 0
+
+		es.Walk(root.Id(), func(e Entity, depth int) bool {
+			visited++
+			return false
+		})
+
+		if visited != 1 {
+			t.Errorf("Expected children to be skipped, visited %d", visited)
+		}
+	})
+
+	t.Run("Removing a parent should remove its children", func(t *testing.T) {
+		es := MakeEntityStore()
+		root := es.New()
+		child := es.New()
+		grandChild := es.New()
+		other := es.New()
+
+		es.SetParent(child.Id(), root.Id())
+		es.SetParent(grandChild.Id(), child.Id())
+
+		es.Remove(root.Id())
+
+		if es.Alive(child.Id()) || es.Alive(grandChild.Id()) {
+			t.Errorf("Expected children to be removed")
+		}
+
+		if !es.Alive(other.Id()) {
+			t.Errorf("Expected unrelated entity to stay alive")
+		}
+	})
+
+	t.Run("Removing a child should detach it from the parent", func(t *testing.T) {
+		es := MakeEntityStore()
+		root := es.New()
+		child := es.New()
+
+		es.SetParent(child.Id(), root.Id())
+		es.Remove(child.Id())
+
+		if len(es.Children(root.Id())) != 0 {
+			t.Errorf("Expected root to have no children")
+		}
+	})
+
+	t.Run("Finder.ChildOf should filter by parent", func(t *testing.T) {
+		es := MakeEntityStore()
+		root := es.New()
+		child := es.New(&_TestComponent{})
+		es.New(&_TestComponent{})
+
+		es.SetParent(child.Id(), root.Id())
+
+		found := MakeFinder(es).Has("TestComponent").ChildOf(root.Id()).GetMany()
+
+		if len(found) != 1 || found[0].Id() != child.Id() {
+			t.Errorf("Expected only the child to be found, got %d entities", len(found))
+		}
+	})
+
+	t.Run("Finder.ChildOf should apply other filters to children", func(t *testing.T) {
+		es := MakeEntityStore()
+		root := es.New()
+		other := es.New()
+
+		tagged := es.New(&_TestComponent{})
+		plain := es.New(&_TestComponent{})
+		foreign := es.New(&_TestComponent{})
+
+		es.SetParent(tagged.Id(), root.Id())
+		es.SetParent(plain.Id(), root.Id())
+		es.SetParent(foreign.Id(), other.Id())
+		tagged.Tag("enemy")
+
+		found := MakeFinder(es).ChildOf(root.Id()).Has("TestComponent").Tagged("enemy").GetMany()
+
+		if len(found) != 1 || found[0].Id() != tagged.Id() {
+			t.Errorf("Expected only the tagged child, got %d entities", len(found))
+		}
+
+		count := 0
+
+		for range MakeFinder(es).ChildOf(root.Id()).Without("value").All() {
+			count++
+		}
+
+		if count != 2 {
+			t.Errorf("Expected All to iterate 2 children, got %d", count)
+		}
+
+		if MakeFinder(es).ChildOf(root.Id()).ChildOf(other.Id()).GetOne() != nil {
+			t.Errorf("Expected no entities with 2 different parents")
+		}
+	})
+
+	t.Run("Relations should be saved & restored", func(t *testing.T) {
+		es := MakeEntityStore()
+		root := es.New()
+		child := es.New()
+
+		es.SetParent(child.Id(), root.Id())
+
+		buf := &bytes.Buffer{}
+		es.SaveBinary(buf)
+
+		restored := MakeEntityStore()
+
+		if err := restored.LoadBinary(buf); err != nil {
+			t.Fatalf("Expected no load error, got %v", err)
+		}
+
+		if p, ok := restored.Parent(child.Id()); !ok || p != root.Id() {
+			t.Errorf("Expected parent %d, got %d (ok=%v)", root.Id(), p, ok)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package engine_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+type _GameConfig struct {
+	Speed float64
+}
+
+// Reads the config resource through the world context.
+type _ResourceSys struct {
+	Speed float64
+
+	SystemBase
+}
+
+func (s *_ResourceSys) Process(es *EntityStore, dt time.Duration) {
+	s.Speed = MustGetResource[*_GameConfig](es.World().Resources).Speed
+}
+
+func TestResources(t *testing.T) {
+	t.Run("Inserted resource should be returned by type", func(t *testing.T) {
+		r := MakeResources()
+		InsertResource(r, &_GameConfig{Speed: 2})
+
+		cfg, ok := GetResource[*_GameConfig](r)
+
+		if !ok || cfg.Speed != 2 {
+			t.Errorf("Expected config with speed 2, got %v (ok=%v)", cfg, ok)
+		}
+	})
+
+	t.Run("Insert should replace the previous resource", func(t *testing.T) {
+		r := MakeResources()
+		InsertResource(r, 1)
+		InsertResource(r, 2)
+
+		if MustGetResource[int](r) != 2 {
+			t.Errorf("Expected 2, got %d", MustGetResource[int](r))
+		}
+	})
+
+	t.Run("Removed resource should not exist", func(t *testing.T) {
+		r := MakeResources()
+		InsertResource(r, "value")
+
+		if !RemoveResource[string](r) {
+			t.Errorf("Expected RemoveResource to return true")
+		}
+
+		if HasResource[string](r) {
+			t.Errorf("Expected resource to be removed")
+		}
+
+		if RemoveResource[string](r) {
+			t.Errorf("Expected RemoveResource to return false for non-existent resource")
+		}
+	})
+
+	t.Run("MustGetResource should panic for non-existent resource", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("Expected panic, got nil")
+			}
+		}()
+
+		MustGetResource[*_GameConfig](MakeResources())
+	})
+
+	t.Run("Systems should reach resources with EntityStore.World", func(t *testing.T) {
+		ecs := MakeECS()
+		sys := &_ResourceSys{SystemBase: *MakeSystemBase("sys_resource", 0, 0)}
+
+		InsertResource(ecs.Resources, &_GameConfig{Speed: 3})
+		ecs.SystemStore.Add(sys)
+		ecs.Process()
+
+		if sys.Speed != 3 {
+			t.Errorf("Expected speed 3, got %v", sys.Speed)
+		}
+	})
+
+	t.Run("Standalone store should have no world", func(t *testing.T) {
+		if MakeEntityStore().World() != nil {
+			t.Errorf("Expected nil world")
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package engine_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+type _PausedResource struct{}
+
+type _ConditionalSys struct {
+	run bool
+
+	_FixedStepSys
+}
+
+func (s *_ConditionalSys) ShouldRun(ecs *ECS) bool {
+	return s.run
+}
+
+// Pauses the game by inserting _PausedResource.
+type _PauseSys struct {
+	SystemBase
+}
+
+func (s *_PauseSys) Process(es *EntityStore, dt time.Duration) {
+	InsertResource(es.World().Resources, &_PausedResource{})
+}
+
+func TestRunConditions(t *testing.T) {
+	t.Run("Disabled systems should be skipped", func(t *testing.T) {
+		ecs := MakeECS()
+		sys := makeFixedStepSys("sys_toggle", 0)
+		ecs.SystemStore.Add(sys)
+
+		ecs.SystemStore.Disable("sys_toggle")
+		ecs.Process()
+
+		if sys.CalledTimes != 0 || ecs.SystemStore.IsEnabled("sys_toggle") {
+			t.Errorf("Expected the disabled system not to be called")
+		}
+
+		ecs.SystemStore.Enable("sys_toggle")
+		ecs.Process()
+
+		if sys.CalledTimes != 1 || !ecs.SystemStore.IsEnabled("sys_toggle") {
+			t.Errorf("Expected the enabled system to be called once, got %d", sys.CalledTimes)
+		}
+	})
+
+	t.Run("Resumed systems should not get the skipped time as dt", func(t *testing.T) {
+		ecs := MakeECS()
+		clock := MakeManualClock(time.Now())
+		sys := makeFixedStepSys("sys_toggle", 0)
+
+		ecs.SetClock(clock)
+		ecs.SystemStore.Add(sys)
+		ecs.SystemStore.Disable("sys_toggle")
+
+		clock.Advance(time.Second)
+		ecs.Process()
+
+		ecs.SystemStore.Enable("sys_toggle")
+		clock.Advance(10 * time.Millisecond)
+		ecs.Process()
+
+		if sys.Dts[0] != 10*time.Millisecond {
+			t.Errorf("Expected dt to be 10ms, got %v", sys.Dts[0])
+		}
+	})
+
+	t.Run("Systems should run only when all run conditions pass", func(t *testing.T) {
+		ecs := MakeECS()
+		sys := makeFixedStepSys("sys_gameplay", 0)
+		ecs.SystemStore.Add(sys)
+
+		ecs.SystemStore.AddRunCondition("sys_gameplay", Not(ResourceExists[*_PausedResource]()))
+		InsertResource(ecs.Resources, &_PausedResource{})
+		ecs.Process()
+
+		if sys.CalledTimes != 0 {
+			t.Errorf("Expected the system not to be called while paused")
+		}
+
+		RemoveResource[*_PausedResource](ecs.Resources)
+		ecs.Process()
+
+		if sys.CalledTimes != 1 {
+			t.Errorf("Expected the system to be called once, got %d", sys.CalledTimes)
+		}
+
+		ecs.SystemStore.AddRunCondition("sys_gameplay", func(ecs *ECS) bool { return false })
+		ecs.Process()
+
+		if sys.CalledTimes != 1 {
+			t.Errorf("Expected the system not to be called with a failing condition")
+		}
+
+		ecs.SystemStore.ClearRunConditions("sys_gameplay")
+		ecs.Process()
+
+		if sys.CalledTimes != 2 {
+			t.Errorf("Expected the system to be called after clearing conditions, got %d", sys.CalledTimes)
+		}
+	})
+
+	t.Run("ShouldRun should skip the system", func(t *testing.T) {
+		ecs := MakeECS()
+		sys := &_ConditionalSys{_FixedStepSys: *makeFixedStepSys("sys_conditional", 0)}
+		ecs.SystemStore.Add(sys)
+
+		ecs.Process()
+		sys.run = true
+		ecs.Process()
+
+		if sys.CalledTimes != 1 {
+			t.Errorf("Expected the system to be called once, got %d", sys.CalledTimes)
+		}
+	})
+
+	t.Run("Disabled systems should be skipped in the fixed step mode", func(t *testing.T) {
+		ecs := MakeECS()
+		sys := makeFixedStepSys("sys_fixed", 0)
+
+		ecs.SystemStore.Add(sys)
+		ecs.SetFixedStep(10*time.Millisecond, 5)
+		ecs.SystemStore.Disable("sys_fixed")
+
+		ecs.Step()
+
+		if sys.CalledTimes != 0 {
+			t.Errorf("Expected the disabled system not to be called")
+		}
+	})
+
+	t.Run("Run conditions should see changes of previous systems", func(t *testing.T) {
+		for _, workers := range []int{1, 2} {
+			ecs := MakeECS()
+			ecs.Workers = workers
+			sys := makeFixedStepSys("sys_pause_menu", 0)
+
+			ecs.SystemStore.Add(&_PauseSys{SystemBase: *MakeSystemBase("sys_pause", 0, 1)})
+			ecs.SystemStore.Add(sys)
+			ecs.SystemStore.AddRunCondition("sys_pause_menu", ResourceExists[*_PausedResource]())
+
+			ecs.Process()
+
+			if sys.CalledTimes != 1 {
+				t.Errorf("Expected the system to be called in the same frame with %d workers", workers)
+			}
+		}
+	})
+}
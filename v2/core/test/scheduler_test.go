@@ -0,0 +1,144 @@
+package engine_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+// Tracks how many access systems are running at the same time.
+type _AccessTracker struct {
+	running    atomic.Int32
+	maxRunning atomic.Int32
+
+	lock  sync.Mutex
+	order []string
+
+	// When set, systems wait until all of them are running, so their overlap doesn't depend on timing.
+	barrier  *sync.WaitGroup
+	timedOut atomic.Bool
+}
+
+func (tr *_AccessTracker) enter(sysType string) {
+	n := tr.running.Add(1)
+
+	for {
+		max := tr.maxRunning.Load()
+
+		if n <= max || tr.maxRunning.CompareAndSwap(max, n) {
+			break
+		}
+	}
+
+	tr.lock.Lock()
+	tr.order = append(tr.order, sysType)
+	tr.lock.Unlock()
+}
+
+// Waits for other systems at the barrier, records a timeout if they don't arrive.
+func (tr *_AccessTracker) wait() {
+	tr.barrier.Done()
+	arrived := make(chan struct{})
+
+	go func() {
+		tr.barrier.Wait()
+		close(arrived)
+	}()
+
+	select {
+	case <-arrived:
+	case <-time.After(time.Second):
+		tr.timedOut.Store(true)
+	}
+}
+
+type _AccessSys struct {
+	reads   []string
+	writes  []string
+	tracker *_AccessTracker
+
+	SystemBase
+}
+
+func (s *_AccessSys) Reads() []string  { return s.reads }
+func (s *_AccessSys) Writes() []string { return s.writes }
+
+func (s *_AccessSys) Process(es *EntityStore, dt time.Duration) {
+	s.tracker.enter(s.Type())
+
+	if s.tracker.barrier != nil {
+		s.tracker.wait()
+	} else {
+		// widens the window to catch unexpected overlap, sequential runs pass anyway
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	s.tracker.running.Add(-1)
+}
+
+func makeAccessSys(tracker *_AccessTracker, sysType string, priority int, reads, writes []string) *_AccessSys {
+	return &_AccessSys{
+		reads:      reads,
+		writes:     writes,
+		tracker:    tracker,
+		SystemBase: *MakeSystemBase(sysType, 0, priority),
+	}
+}
+
+func TestParallelScheduler(t *testing.T) {
+	t.Run("Non-conflicting systems should run in parallel", func(t *testing.T) {
+		ecs := MakeECS()
+		ecs.Workers = 4
+		tracker := &_AccessTracker{barrier: &sync.WaitGroup{}}
+		tracker.barrier.Add(2)
+
+		ecs.SystemStore.Add(makeAccessSys(tracker, "sys_ai", 1, []string{"position"}, []string{"brain"}))
+		ecs.SystemStore.Add(makeAccessSys(tracker, "sys_physics", 0, []string{"position"}, []string{"velocity"}))
+
+		ecs.Process()
+
+		if tracker.timedOut.Load() || tracker.maxRunning.Load() != 2 {
+			t.Errorf("Expected 2 systems to run at the same time, got %d", tracker.maxRunning.Load())
+		}
+	})
+
+	t.Run("Conflicting systems should run one by one in priority order", func(t *testing.T) {
+		ecs := MakeECS()
+		ecs.Workers = 4
+		tracker := &_AccessTracker{}
+
+		ecs.SystemStore.Add(makeAccessSys(tracker, "sys_read", 0, []string{"position"}, nil))
+		ecs.SystemStore.Add(makeAccessSys(tracker, "sys_write", 1, nil, []string{"position"}))
+
+		ecs.Process()
+
+		if tracker.maxRunning.Load() != 1 {
+			t.Errorf("Expected systems to run alone, got %d at the same time", tracker.maxRunning.Load())
+		}
+
+		if tracker.order[0] != "sys_write" || tracker.order[1] != "sys_read" {
+			t.Errorf("Expected sys_write before sys_read, got %v", tracker.order)
+		}
+	})
+
+	t.Run("Systems without declared access should run alone", func(t *testing.T) {
+		ecs := MakeECS()
+		ecs.Workers = 4
+
+		var prevCallIndex int8 = -1
+		sysA := make_TEST_CORE_SYS_A(&prevCallIndex)
+		tracker := &_AccessTracker{}
+
+		ecs.SystemStore.Add(sysA)
+		ecs.SystemStore.Add(makeAccessSys(tracker, "sys_access", 1, nil, []string{"velocity"}))
+
+		ecs.Process()
+
+		if sysA.CalledTimes != 1 || len(tracker.order) != 1 {
+			t.Errorf("Expected both systems to be called once")
+		}
+	})
+}
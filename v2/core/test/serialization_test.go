@@ -0,0 +1,183 @@
+package engine_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+// Creates a store with a removed entity, so restored IDs have generations & free slots.
+func makeSavedStore() (*EntityStore, Entity, Entity) {
+	RegisterComponentType[*_ValueComponent]()
+	RegisterComponent(func() Component { return &_TestComponent{} })
+
+	es := MakeEntityStore()
+	removed := es.New(&_ValueComponent{Value: 1})
+	es.Remove(removed.Id())
+
+	e1 := es.New(&_ValueComponent{Value: 2}, &_TestComponent{})
+	e2 := es.New(&_ValueComponent{Value: 3})
+
+	return es, e1, e2
+}
+
+func TestSerialization(t *testing.T) {
+	formats := []struct {
+		name string
+		save func(es *EntityStore, w *bytes.Buffer) error
+		load func(es *EntityStore, r *bytes.Buffer) error
+	}{
+		{
+			"JSON",
+			func(es *EntityStore, w *bytes.Buffer) error { return es.SaveJSON(w) },
+			func(es *EntityStore, r *bytes.Buffer) error { return es.LoadJSON(r) },
+		},
+		{
+			"Binary",
+			func(es *EntityStore, w *bytes.Buffer) error { return es.SaveBinary(w) },
+			func(es *EntityStore, r *bytes.Buffer) error { return es.LoadBinary(r) },
+		},
+	}
+
+	for _, f := range formats {
+		t.Run(f.name+" restore should preserve IDs & components", func(t *testing.T) {
+			es, e1, e2 := makeSavedStore()
+			buf := &bytes.Buffer{}
+
+			if err := f.save(es, buf); err != nil {
+				t.Fatalf("Expected no save error, got %v", err)
+			}
+
+			restored := MakeEntityStore()
+
+			if err := f.load(restored, buf); err != nil {
+				t.Fatalf("Expected no load error, got %v", err)
+			}
+
+			if len(restored.GetAll()) != 2 {
+				t.Errorf("Expected 2 entities, got %d", len(restored.GetAll()))
+			}
+
+			for i, e := range []Entity{e1, e2} {
+				if !restored.Alive(e.Id()) {
+					t.Errorf("Expected entity %d to be restored", e.Id())
+					continue
+				}
+
+				comps := restored.GetById(e.Id())
+
+				if len(comps) != len(e.GetAll()) {
+					t.Errorf("Expected %d components, got %d", len(e.GetAll()), len(comps))
+				}
+
+				for _, c := range comps {
+					if val, ok := c.(*_ValueComponent); ok && val.Value != i+2 {
+						t.Errorf("Expected value %d, got %d", i+2, val.Value)
+					}
+				}
+			}
+
+			// both stores should reuse the same slot & generation
+			if es.New().Id() != restored.New().Id() {
+				t.Errorf("Expected restored store to allocate the same IDs")
+			}
+		})
+
+		t.Run(f.name+" restore should preserve tags", func(t *testing.T) {
+			es, e1, _ := makeSavedStore()
+			es.Tag(e1.Id(), "enemy", "selected")
+			buf := &bytes.Buffer{}
+			f.save(es, buf)
+
+			restored := MakeEntityStore()
+
+			if err := f.load(restored, buf); err != nil {
+				t.Fatalf("Expected no load error, got %v", err)
+			}
+
+			if !restored.HasTag(e1.Id(), "enemy", "selected") {
+				t.Errorf("Expected tags to be restored, got %v", restored.Tags(e1.Id()))
+			}
+		})
+
+		t.Run(f.name+" load into non-empty store should fail", func(t *testing.T) {
+			es, _, _ := makeSavedStore()
+			buf := &bytes.Buffer{}
+			f.save(es, buf)
+
+			if err := f.load(es, buf); !errors.Is(err, ErrStoreNotEmpty) {
+				t.Errorf("Expected ErrStoreNotEmpty, got %v", err)
+			}
+		})
+	}
+
+	t.Run("Unregistered component should fail to load", func(t *testing.T) {
+		es := MakeEntityStore()
+		es.New(&_TestComponent2{})
+
+		buf := &bytes.Buffer{}
+		es.SaveJSON(buf)
+
+		if err := MakeEntityStore().LoadJSON(buf); !errors.Is(err, ErrUnknownComponent) {
+			t.Errorf("Expected ErrUnknownComponent, got %v", err)
+		}
+	})
+
+	t.Run("Corrupted binary data should fail to load", func(t *testing.T) {
+		err := MakeEntityStore().LoadBinary(bytes.NewBufferString("not a save"))
+
+		if !errors.Is(err, ErrInvalidSave) {
+			t.Errorf("Expected ErrInvalidSave, got %v", err)
+		}
+	})
+
+	t.Run("Invalid slot counts should fail to load", func(t *testing.T) {
+		saves := []string{
+			`{"maxId": 4294967297, "generations": [], "entities": []}`,
+			`{"maxId": 1000000, "generations": [0], "entities": []}`,
+			`{"maxId": 1, "generations": [0], "entities": [{"id": 0, "components": {}}, {"id": 1, "components": {}}]}`,
+		}
+
+		for _, save := range saves {
+			if err := MakeEntityStore().LoadJSON(bytes.NewBufferString(save)); !errors.Is(err, ErrInvalidSave) {
+				t.Errorf("Expected ErrInvalidSave for %s, got %v", save, err)
+			}
+		}
+
+		// header, max ID over 32 bits & no generations
+		binarySave := []byte{'E', 'C', 'S', 1, 0x81, 0x80, 0x80, 0x80, 0x10, 0}
+
+		if err := MakeEntityStore().LoadBinary(bytes.NewBuffer(binarySave)); !errors.Is(err, ErrInvalidSave) {
+			t.Errorf("Expected ErrInvalidSave for a huge binary max ID, got %v", err)
+		}
+
+		// header, 1<<32 slots & generations without data
+		truncated := []byte{'E', 'C', 'S', 1, 0x80, 0x80, 0x80, 0x80, 0x10, 0x80, 0x80, 0x80, 0x80, 0x10}
+
+		if err := MakeEntityStore().LoadBinary(bytes.NewBuffer(truncated)); !errors.Is(err, ErrInvalidSave) {
+			t.Errorf("Expected ErrInvalidSave for truncated generations, got %v", err)
+		}
+	})
+
+	t.Run("Invalid children should fail to load without restoring entities", func(t *testing.T) {
+		saves := []string{
+			`{"maxId": 2, "generations": [0, 0], "entities": [{"id": 0, "components": {}, "children": [7]}]}`,
+			`{"maxId": 2, "generations": [0, 0], "entities": [{"id": 0, "components": {}, "children": [1]}, {"id": 1, "components": {}, "children": [0]}]}`,
+			`{"maxId": 3, "generations": [0, 0, 0], "entities": [{"id": 0, "components": {}, "children": [2]}, {"id": 1, "components": {}, "children": [2]}, {"id": 2, "components": {}}]}`,
+		}
+
+		for _, save := range saves {
+			es := MakeEntityStore()
+
+			if err := es.LoadJSON(bytes.NewBufferString(save)); !errors.Is(err, ErrInvalidSave) {
+				t.Errorf("Expected ErrInvalidSave for %s, got %v", save, err)
+			}
+
+			if len(es.GetAll()) != 0 {
+				t.Errorf("Expected no restored entities, got %d", len(es.GetAll()))
+			}
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package engine_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+// Component with a configurable type, used to register many component types.
+type _NamedComponent struct {
+	name string
+}
+
+func (c *_NamedComponent) Type() string {
+	return c.name
+}
+
+// Creates n components with distinct types "named_0", "named_1"...
+func makeNamedComponents(n int) []Component {
+	comps := make([]Component, n)
+
+	for i := range comps {
+		comps[i] = &_NamedComponent{name: fmt.Sprintf("named_%d", i)}
+	}
+
+	return comps
+}
+
+func TestComponentIDs(t *testing.T) {
+	t.Run("Component types should get IDs on first use", func(t *testing.T) {
+		es := MakeEntityStore()
+
+		if _, ok := es.ComponentID("value"); ok {
+			t.Errorf("Expected no ID for an unused type")
+		}
+
+		es.New(&_ValueComponent{}, &_TestComponent{})
+		id, ok := es.ComponentID("value")
+		es.New(&_ValueComponent{})
+
+		if again, _ := es.ComponentID("value"); !ok || again != id {
+			t.Errorf("Expected a stable ID, got %d & %d", id, again)
+		}
+	})
+
+	t.Run("Has should match signatures wider than a word", func(t *testing.T) {
+		es := MakeEntityStore()
+		comps := makeNamedComponents(130)
+		e := es.New(comps[0], comps[70], comps[129])
+
+		if !e.Has("named_0", "named_70", "named_129") || e.Has("named_1") || e.Has("unknown") {
+			t.Errorf("Expected only attached types to match")
+		}
+	})
+}
+
+func TestFinderSignatures(t *testing.T) {
+	t.Run("Finder filters should match signatures wider than a word", func(t *testing.T) {
+		es := MakeEntityStore()
+		comps := makeNamedComponents(100)
+		es.New(comps[:80]...)
+
+		a := es.New(&_NamedComponent{name: "named_75"})
+		b := es.New(&_NamedComponent{name: "named_75"}, &_NamedComponent{name: "named_99"})
+
+		found := MakeFinder(es).Has("named_75").Without("named_0", "never_used").GetMany()
+
+		if len(found) != 2 {
+			t.Errorf("Expected 2 entities, got %d", len(found))
+		}
+
+		found = MakeFinder(es).AnyOf("named_99", "never_used").GetMany()
+
+		if len(found) != 1 || found[0].Id() != b.Id() {
+			t.Errorf("Expected only entity with named_99, got %v", found)
+		}
+
+		if MakeFinder(es).Has("named_75", "never_used").GetOne() != nil {
+			t.Errorf("Expected no entities with a never used type")
+		}
+
+		a.Tag("enemy")
+		count := 0
+
+		for range MakeFinder(es).Has("named_75").Tagged("enemy").All() {
+			count++
+		}
+
+		if count != 1 {
+			t.Errorf("Expected All to apply tag filters, got %d entities", count)
+		}
+	})
+
+	t.Run("Observers should match types used after they were added", func(t *testing.T) {
+		es := MakeEntityStore()
+		observer := &_RecordingObserver{}
+		observer.SetObservedTypes("named_70")
+		es.AddObserver(observer)
+
+		e := es.New(makeNamedComponents(71)...)
+		e.Remove("named_70", "named_69")
+
+		if observer.Attached != 1 || observer.Detached != 1 {
+			t.Errorf("Expected 1 attach & detach, got %d & %d", observer.Attached, observer.Detached)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package engine_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+type _OrderedSys struct {
+	stage  Stage
+	before []string
+	after  []string
+	calls  *[]string
+
+	SystemBase
+}
+
+func (s *_OrderedSys) Stage() Stage     { return s.stage }
+func (s *_OrderedSys) Before() []string { return s.before }
+func (s *_OrderedSys) After() []string  { return s.after }
+
+func (s *_OrderedSys) Process(es *EntityStore, dt time.Duration) {
+	*s.calls = append(*s.calls, s.Type())
+}
+
+func makeOrderedSys(calls *[]string, sysType string, stage Stage, before, after []string) *_OrderedSys {
+	return &_OrderedSys{
+		stage:      stage,
+		before:     before,
+		after:      after,
+		calls:      calls,
+		SystemBase: *MakeSystemBase(sysType, 0, 0),
+	}
+}
+
+func TestSystemStages(t *testing.T) {
+	t.Run("Systems should be processed by stages", func(t *testing.T) {
+		ecs := MakeECS()
+		calls := make([]string, 0)
+
+		ecs.SystemStore.Add(makeOrderedSys(&calls, "sys_render", StageRender, nil, nil))
+		ecs.SystemStore.Add(makeOrderedSys(&calls, "sys_input", StagePreUpdate, nil, nil))
+		ecs.SystemStore.Add(makeOrderedSys(&calls, "sys_sync", StagePostUpdate, nil, nil))
+
+		ecs.Process()
+
+		expected := []string{"sys_input", "sys_sync", "sys_render"}
+
+		if !slices.Equal(calls, expected) {
+			t.Errorf("Expected calls %v, got %v", expected, calls)
+		}
+
+		if !slices.Equal(ecs.ExecutionOrder(), expected) {
+			t.Errorf("Expected execution order %v, got %v", expected, ecs.ExecutionOrder())
+		}
+	})
+
+	t.Run("Before & After should override priority", func(t *testing.T) {
+		ecs := MakeECS()
+		calls := make([]string, 0)
+
+		ecs.SystemStore.Add(makeOrderedSys(&calls, "sys_move", StageUpdate, nil, []string{"sys_physics"}))
+		ecs.SystemStore.Add(makeOrderedSys(&calls, "sys_ai", StageUpdate, []string{"sys_physics"}, nil))
+		ecs.SystemStore.Add(makeOrderedSys(&calls, "sys_physics", StageUpdate, nil, nil))
+
+		expected := []string{"sys_ai", "sys_physics", "sys_move"}
+
+		if !slices.Equal(ecs.ExecutionOrder(), expected) {
+			t.Errorf("Expected execution order %v, got %v", expected, ecs.ExecutionOrder())
+		}
+	})
+
+	t.Run("Systems without ordering should be in the update stage sorted by priority", func(t *testing.T) {
+		ecs := MakeECS()
+		calls := make([]string, 0)
+		var prevCallIndex int8 = -1
+
+		ecs.SystemStore.Add(make_TEST_CORE_SYS_A(&prevCallIndex))
+		ecs.SystemStore.Add(makeOrderedSys(&calls, "sys_late", StagePostUpdate, nil, nil))
+		ecs.SystemStore.Add(makeOrderedSys(&calls, "sys_early", StagePreUpdate, nil, nil))
+
+		order := ecs.ExecutionOrder()
+
+		if order[0] != "sys_early" || order[2] != "sys_late" {
+			t.Errorf("Expected the system without ordering in the middle, got %v", order)
+		}
+	})
+
+	t.Run("Add should return an error on a cycle", func(t *testing.T) {
+		ecs := MakeECS()
+		calls := make([]string, 0)
+
+		ecs.SystemStore.Add(makeOrderedSys(&calls, "sys_a", StageUpdate, []string{"sys_b"}, nil))
+		err := ecs.SystemStore.Add(makeOrderedSys(&calls, "sys_b", StageUpdate, []string{"sys_a"}, nil))
+
+		if !errors.Is(err, ErrSystemCycle) {
+			t.Errorf("Expected ErrSystemCycle, got %v", err)
+		}
+
+		if ecs.SystemStore.Get("sys_b") != nil {
+			t.Errorf("Expected the system not to be added")
+		}
+	})
+
+	t.Run("Add should return an error on a constraint against the stage order", func(t *testing.T) {
+		ecs := MakeECS()
+		calls := make([]string, 0)
+
+		ecs.SystemStore.Add(makeOrderedSys(&calls, "sys_input", StagePreUpdate, nil, nil))
+		err := ecs.SystemStore.Add(makeOrderedSys(&calls, "sys_render", StageRender, []string{"sys_input"}, nil))
+
+		if !errors.Is(err, ErrSystemCycle) {
+			t.Errorf("Expected ErrSystemCycle, got %v", err)
+		}
+	})
+
+	t.Run("Ordered systems should not run in the same parallel batch", func(t *testing.T) {
+		ecs := MakeECS()
+		ecs.Workers = 4
+		tracker := &_AccessTracker{}
+
+		ecs.SystemStore.Add(&_OrderedAccessSys{
+			after:      []string{"sys_physics"},
+			_AccessSys: *makeAccessSys(tracker, "sys_ai", 1, []string{"position"}, []string{"brain"}),
+		})
+		ecs.SystemStore.Add(makeAccessSys(tracker, "sys_physics", 0, []string{"position"}, []string{"velocity"}))
+
+		ecs.Process()
+
+		if tracker.maxRunning.Load() != 1 {
+			t.Errorf("Expected systems to run alone, got %d at the same time", tracker.maxRunning.Load())
+		}
+
+		if !slices.Equal(tracker.order, []string{"sys_physics", "sys_ai"}) {
+			t.Errorf("Expected sys_physics before sys_ai, got %v", tracker.order)
+		}
+	})
+}
+
+type _OrderedAccessSys struct {
+	after []string
+
+	_AccessSys
+}
+
+func (s *_OrderedAccessSys) Stage() Stage     { return StageUpdate }
+func (s *_OrderedAccessSys) Before() []string { return nil }
+func (s *_OrderedAccessSys) After() []string  { return s.after }
@@ -0,0 +1,112 @@
+package engine_test
+
+import (
+	"slices"
+	"testing"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+func TestStateMachine(t *testing.T) {
+	t.Run("Set should be applied at the start of the next Process", func(t *testing.T) {
+		ecs := MakeECS()
+		ecs.States.Set("menu")
+
+		if ecs.States.Current() != "" {
+			t.Errorf("Expected no state before Process, got %s", ecs.States.Current())
+		}
+
+		if next, ok := ecs.States.Next(); !ok || next != "menu" {
+			t.Errorf("Expected the menu state to be queued")
+		}
+
+		ecs.Process()
+
+		if ecs.States.Current() != "menu" {
+			t.Errorf("Expected the menu state, got %s", ecs.States.Current())
+		}
+	})
+
+	t.Run("Exit hooks should run before enter hooks", func(t *testing.T) {
+		ecs := MakeECS()
+		calls := make([]string, 0)
+
+		ecs.States.OnExit("menu", func(ecs *ECS) {
+			calls = append(calls, "exit "+ecs.States.Current())
+		})
+
+		ecs.States.OnEnter("playing", func(ecs *ECS) {
+			calls = append(calls, "enter "+ecs.States.Current())
+		})
+
+		ecs.States.Set("menu")
+		ecs.Process()
+		ecs.States.Set("playing")
+		ecs.Process()
+
+		expected := []string{"exit menu", "enter playing"}
+
+		if !slices.Equal(calls, expected) {
+			t.Errorf("Expected calls %v, got %v", expected, calls)
+		}
+	})
+
+	t.Run("Systems added in a state should run only in that state", func(t *testing.T) {
+		ecs := MakeECS()
+		sys := makeFixedStepSys("sys_gameplay", 0)
+		ecs.SystemStore.AddInState(sys, "playing")
+
+		ecs.States.Set("menu")
+		ecs.Process()
+
+		if sys.CalledTimes != 0 {
+			t.Errorf("Expected the system not to be called in the menu state")
+		}
+
+		ecs.States.Set("playing")
+		ecs.Process()
+
+		if sys.CalledTimes != 1 {
+			t.Errorf("Expected the system to be called in the playing state, got %d", sys.CalledTimes)
+		}
+
+		ecs.States.Set("paused")
+		ecs.Process()
+
+		if sys.CalledTimes != 1 {
+			t.Errorf("Expected the system not to be called in the paused state")
+		}
+	})
+
+	t.Run("Enter hook commands should be applied before systems", func(t *testing.T) {
+		ecs := MakeECS()
+
+		ecs.States.OnEnter("loading", func(ecs *ECS) {
+			ecs.EntityStore.Commands().New(&_TestComponent{})
+		})
+
+		ecs.States.Set("loading")
+		ecs.Process()
+
+		if len(MakeFinder(&ecs.EntityStore).Has("TestComponent").GetMany()) != 1 {
+			t.Errorf("Expected the entity to be created on enter")
+		}
+	})
+
+	t.Run("Setting the current state should cancel the queued transition", func(t *testing.T) {
+		ecs := MakeECS()
+		entered := 0
+
+		ecs.States.OnEnter("playing", func(ecs *ECS) { entered++ })
+
+		ecs.States.Set("playing")
+		ecs.Process()
+		ecs.States.Set("paused")
+		ecs.States.Set("playing")
+		ecs.Process()
+
+		if entered != 1 || ecs.States.Current() != "playing" {
+			t.Errorf("Expected the playing state to be entered once, got %d", entered)
+		}
+	})
+}
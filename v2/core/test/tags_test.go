@@ -0,0 +1,157 @@
+package engine_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+func TestTags(t *testing.T) {
+	t.Run("Tag should mark the entity without attaching components", func(t *testing.T) {
+		es := MakeEntityStore()
+		e := es.New(&_TestComponent{})
+
+		e.Tag("enemy", "selected")
+
+		if !e.HasTag("enemy", "selected") || len(e.GetAll()) != 1 {
+			t.Errorf("Expected tags & a single component")
+		}
+
+		e.Untag("selected")
+
+		if e.HasTag("selected") || !e.HasTag("enemy") || e.HasTag("unknown") {
+			t.Errorf("Expected only the enemy tag, got %v", es.Tags(e.Id()))
+		}
+	})
+
+	t.Run("Tag should fail for removed entities", func(t *testing.T) {
+		es := MakeEntityStore()
+		e := es.New()
+		e.Tag("enemy")
+		es.Remove(e.Id())
+
+		if err := e.Tag("enemy"); !errors.Is(err, ErrEntityNotAlive) {
+			t.Errorf("Expected ErrEntityNotAlive, got %v", err)
+		}
+
+		// the slot is reused without tags
+		if reused := es.New(); reused.HasTag("enemy") {
+			t.Errorf("Expected a reused slot not to keep tags")
+		}
+	})
+
+	t.Run("Tags should be listed in registration order", func(t *testing.T) {
+		es := MakeEntityStore()
+		e := es.New()
+
+		for i := 0; i < 100; i++ {
+			es.New().Tag(string(rune('a' + i%26)))
+		}
+
+		e.Tag("z", "a", "late")
+
+		if tags := es.Tags(e.Id()); !slices.Equal(tags, []string{"a", "z", "late"}) {
+			t.Errorf("Expected [a z late], got %v", tags)
+		}
+	})
+
+	t.Run("Commands should record tag changes", func(t *testing.T) {
+		es := MakeEntityStore()
+		e := es.New()
+
+		es.Commands().Tag(e.Id(), "enemy", "selected")
+		es.Commands().Untag(e.Id(), "selected")
+		es.Commands().Apply()
+
+		if !e.HasTag("enemy") || e.HasTag("selected") {
+			t.Errorf("Expected only the enemy tag, got %v", es.Tags(e.Id()))
+		}
+	})
+
+	t.Run("Duplicate should copy tags", func(t *testing.T) {
+		es := MakeEntityStore()
+		e := es.New()
+		e.Tag("enemy")
+
+		dup, _ := es.Duplicate(e.Id())
+
+		if !dup.HasTag("enemy") {
+			t.Errorf("Expected the copy to be tagged")
+		}
+	})
+}
+
+// Records tag notifications, implements TagObserver.
+type _RecordingTagObserver struct {
+	_RecordingObserver
+	tags []string
+}
+
+func (o *_RecordingTagObserver) GetObservedTags() []string { return o.tags }
+
+func TestTagObservers(t *testing.T) {
+	t.Run("Observers should be notified about tag changes", func(t *testing.T) {
+		es := MakeEntityStore()
+		observer := &_RecordingTagObserver{tags: []string{"enemy"}}
+		es.AddObserver(observer)
+
+		e := es.New()
+		e.Tag("enemy", "selected")
+		e.Tag("enemy")
+
+		if observer.Attached != 1 {
+			t.Errorf("Expected a single attach notification, got %d", observer.Attached)
+		}
+
+		es.Remove(e.Id())
+
+		if observer.Detached != 1 {
+			t.Errorf("Expected a detach notification on removal, got %d", observer.Detached)
+		}
+	})
+
+	t.Run("Component observers should not be notified about tags with the same name", func(t *testing.T) {
+		es := MakeEntityStore()
+		observer := &_RecordingObserver{}
+		observer.SetObservedTypes("value")
+		es.AddObserver(observer)
+
+		e := es.New()
+		e.Tag("value")
+		e.Untag("value")
+
+		if observer.Attached != 0 || observer.Detached != 0 {
+			t.Errorf("Expected no notifications, got %d & %d", observer.Attached, observer.Detached)
+		}
+	})
+}
+
+func TestFinderTags(t *testing.T) {
+	t.Run("Finder should filter entities by tags", func(t *testing.T) {
+		es := MakeEntityStore()
+
+		enemy := es.New(&_TestComponent{})
+		enemy.Tag("enemy")
+
+		selected := es.New(&_TestComponent{})
+		selected.Tag("enemy", "selected")
+
+		es.New(&_TestComponent{})
+
+		if n := len(MakeFinder(es).Has("TestComponent").Tagged("enemy").GetMany()); n != 2 {
+			t.Errorf("Expected 2 enemies, got %d", n)
+		}
+
+		found := MakeFinder(es).Tagged("enemy").NotTagged("selected", "unknown").GetMany()
+
+		if len(found) != 1 || found[0].Id() != enemy.Id() {
+			t.Errorf("Expected only the not selected enemy, got %v", found)
+		}
+
+		if n := len(MakeFinder(es).Tagged("unknown").GetMany()); n != 0 {
+			t.Errorf("Expected no entities with an unknown tag, got %d", n)
+		}
+	})
+}
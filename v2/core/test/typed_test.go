@@ -0,0 +1,64 @@
+package engine_test
+
+import (
+	"testing"
+
+	. "github.com/kostayne/ecs/v2/core"
+)
+
+func TestTypeOf(t *testing.T) {
+	t.Run("TypeOf should return component type string", func(t *testing.T) {
+		if TypeOf[*_ValueComponent]() != "value" {
+			t.Errorf("Expected 'value', got '%s'", TypeOf[*_ValueComponent]())
+		}
+	})
+
+	t.Run("TypeOf should panic for interfaces", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("Expected panic, got nil")
+			}
+		}()
+
+		TypeOf[Component]()
+	})
+}
+
+func TestTypedAccess(t *testing.T) {
+	es := MakeEntityStore()
+	e := es.New(&_ValueComponent{Value: 7})
+
+	t.Run("Get should return typed component", func(t *testing.T) {
+		val, ok := Get[*_ValueComponent](e)
+
+		if !ok || val.Value != 7 {
+			t.Errorf("Expected value component with 7, got %v (ok=%v)", val, ok)
+		}
+	})
+
+	t.Run("Get should return false for non-existent component", func(t *testing.T) {
+		if _, ok := Get[*_TestComponent](e); ok {
+			t.Errorf("Expected ok to be false")
+		}
+	})
+
+	t.Run("Add & Has should work with typed components", func(t *testing.T) {
+		Add(e, &_TestComponent{})
+
+		if !Has[*_TestComponent](e) {
+			t.Errorf("Expected entity to have TestComponent")
+		}
+
+		if !e.Has("TestComponent") {
+			t.Errorf("Expected string based Has to see TestComponent")
+		}
+	})
+
+	t.Run("Remove should detach typed component", func(t *testing.T) {
+		Remove[*_TestComponent](e)
+
+		if Has[*_TestComponent](e) {
+			t.Errorf("Expected entity not to have TestComponent")
+		}
+	})
+}
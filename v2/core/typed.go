@@ -0,0 +1,75 @@
+package core
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Internal cache of component type strings by Go type.
+var componentTypeCache sync.Map
+
+// Returns the component type string (Component.Type()) of T.
+// T is usually a pointer to a component struct, e.g. TypeOf[*PositionComponent]().
+func TypeOf[T Component]() ComponentType {
+	rt := reflect.TypeFor[T]()
+
+	if t, ok := componentTypeCache.Load(rt); ok {
+		return t.(ComponentType)
+	}
+
+	var c Component
+
+	switch rt.Kind() {
+	case reflect.Interface:
+		panic("Component type can't be derived from interface " + rt.String())
+	case reflect.Pointer:
+		c = reflect.New(rt.Elem()).Interface().(Component)
+	default:
+		var zero T
+		c = zero
+	}
+
+	t := c.Type()
+	componentTypeCache.Store(rt, t)
+
+	return t
+}
+
+// Returns a component of type T attached to the entity, ok is false if no such component exists.
+func Get[T Component](e Entity) (T, bool) {
+	var zero T
+	c := e.GetOne(TypeOf[T]())
+
+	if c == nil {
+		return zero, false
+	}
+
+	typed, ok := (*c).(T)
+
+	if !ok {
+		return zero, false
+	}
+
+	return typed, true
+}
+
+// Returns true if the entity has a component of type T attached.
+func Has[T Component](e Entity) bool {
+	return e.Has(TypeOf[T]())
+}
+
+// Attaches provided typed components to the entity.
+func Add[T Component](e Entity, components ...T) error {
+	for _, c := range components {
+		if err := e.Add(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Detaches a component of type T from the entity.
+func Remove[T Component](e Entity) error {
+	return e.Remove(TypeOf[T]())
+}
@@ -23,7 +23,7 @@ func main() {
 	ecs.SystemStore.Add(moveSys)
 
 	// getting a component to display
-	plPos := (*player.GetOne("position")).(*PositionComponent)
+	plPos, _ := core.Get[*PositionComponent](player)
 
 	ecs.Setup()
 
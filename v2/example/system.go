@@ -13,19 +13,15 @@ type MovementSystem struct {
 
 // Main logic
 func (s *MovementSystem) Process(es *core.EntityStore, dt time.Duration) {
-	finder := core.MakeFinder(es)
-	entities := finder.Has("position").GetMany()
+	// cached query, it's kept up to date by the entity store
+	query := es.Query("position")
 
-	for _, e := range entities {
-		comp := *e.GetOne("position")
-		pos := comp.(*PositionComponent)
-
-		// one line version
-		// pos := (*e.Get("position")).(*PositionComponent)
+	query.Each(func(e core.Entity, comps []core.Component) {
+		pos := comps[0].(*PositionComponent)
 
 		pos.X += 1
 		pos.Y += 2
-	}
+	})
 }
 
 func MakeMovementSystem() *MovementSystem {